@@ -0,0 +1,63 @@
+package main
+
+import (
+	"got/config"
+	"got/utils"
+)
+
+// commandIndex returns the index of the command in the arguments, which is
+// the first argument that is not a flag or the value of a flag, or -1 if there
+// is none.
+func commandIndex(args []string) int {
+	fs := makeFlagSet()
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		}
+
+		name, _, hasValue, ok := fs.Lookup(args[i])
+		if !ok {
+			return i
+		} else if !hasValue && !fs.IsBool(name) {
+			// the next argument is the value of the flag
+			i++
+		}
+	}
+	return -1
+}
+
+// expandAlias replaces the command in the arguments by what it expands to,
+// if it is an alias.
+func expandAlias(args []string, aliases []config.Alias) ([]string, error) {
+	i := commandIndex(args)
+	if i < 0 {
+		return args, nil
+	}
+
+	for _, alias := range aliases {
+		if alias.Name != args[i] {
+			continue
+		}
+
+		words, err := utils.SplitWords(alias.Command)
+		if err != nil {
+			return nil, err
+		}
+
+		// the words of the alias are parsed as usual, also when the alias
+		// is after a "--"
+		before, after := args[:i], args[i+1:]
+		if i > 0 && args[i-1] == "--" {
+			before, after = args[:i-1], append([]string{"--"}, after...)
+		}
+
+		res := append([]string{}, before...)
+		res = append(res, words...)
+		return append(res, after...), nil
+	}
+
+	return args, nil
+}
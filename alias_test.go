@@ -0,0 +1,59 @@
+package main
+
+import (
+	"got/config"
+	"strings"
+	"testing"
+)
+
+func TestCommandIndex(t *testing.T) {
+	tests := []struct {
+		args     string
+		expected int
+	}{
+		{"standup", 0},
+		{"-y standup", 1},
+		{"--yes standup", 1},
+		{"--tz=UTC standup", 1},
+		{"--tz UTC standup", 2},
+		{"--output=json hello world", 1},
+		{"-s 9:00 in", 2},
+		{"-s=9:00 in", 1},
+		{"--yes=false in", 1},
+		{"-- standup", 1},
+		{"-y --", -1},
+		{"--tz UTC", -1},
+		{"", -1},
+	}
+
+	for _, test := range tests {
+		if i := commandIndex(strings.Fields(test.args)); i != test.expected {
+			t.Errorf("the command of %q is at %d, expected %d", test.args, i, test.expected)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := []config.Alias{{Name: "standup", Command: "in --note 'daily standup'"}}
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"standup"}, []string{"in", "--note", "daily standup"}},
+		{[]string{"-y", "standup", "--at", "9:00"}, []string{"-y", "in", "--note", "daily standup", "--at", "9:00"}},
+		{[]string{"--tz=UTC", "standup"}, []string{"--tz=UTC", "in", "--note", "daily standup"}},
+		{[]string{"--", "standup", "-x"}, []string{"in", "--note", "daily standup", "--", "-x"}},
+		{[]string{"--note", "standup", "in"}, []string{"--note", "standup", "in"}},
+		{[]string{"display"}, []string{"display"}},
+	}
+
+	for _, test := range tests {
+		res, err := expandAlias(test.args, aliases)
+		if err != nil {
+			t.Errorf("%q: %s", test.args, err)
+		} else if strings.Join(res, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%q expands to %q, expected %q", test.args, res, test.expected)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"got/config"
+	"got/types"
+	"got/utils"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+type balanceWeek struct {
+	Start    time.Time
+	Expected time.Duration
+	Worked   time.Duration
+}
+
+// computeBalance returns the expected and worked time for every week between
+// the days of since and until, both inclusive.
+func computeBalance(schedule *config.Schedule, entries []*types.Entry, since, until time.Time, weekStart time.Weekday) []*balanceWeek {
+	worked := make(map[string]time.Duration)
+	for _, entry := range entries {
+		duration, _ := entry.Duration()
+		worked[entry.Start.Format("2006-01-02")] += duration
+	}
+
+	var res []*balanceWeek
+	var week *balanceWeek
+
+	last := utils.StartOfDay(until)
+	for day := utils.StartOfDay(since); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if start := utils.StartOfWeek(day, weekStart); week == nil || !start.Equal(week.Start) {
+			week = &balanceWeek{Start: start}
+			res = append(res, week)
+		}
+
+		week.Expected += schedule.Expected(day)
+		week.Worked += worked[day.Format("2006-01-02")]
+	}
+
+	return res
+}
+
+func writeBalance(out io.Writer, weeks []*balanceWeek) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Week\tStart\tExpected\tWorked\tDifference\tBalance")
+
+	var expected, worked, balance time.Duration
+	for _, week := range weeks {
+		expected += week.Expected
+		worked += week.Worked
+		balance += week.Worked - week.Expected
+
+		year, number := week.Start.ISOWeek()
+		fmt.Fprintf(
+			w,
+			"%d-W%02d\t%s\t%s\t%s\t%s\t%s\n",
+			year,
+			number,
+			week.Start.Format("Mon Jan 2, 2006"),
+			utils.FormatDuration(week.Expected),
+			utils.FormatDuration(week.Worked),
+			utils.FormatSignedDuration(week.Worked-week.Expected),
+			utils.FormatSignedDuration(balance),
+		)
+	}
+
+	fmt.Fprintf(w, "\t\t\t\t\t\n")
+	fmt.Fprintf(
+		w,
+		"Total\t\t%s\t%s\t\t%s\n",
+		utils.FormatDuration(expected),
+		utils.FormatDuration(worked),
+		utils.FormatSignedDuration(balance),
+	)
+
+	return w.Flush()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestComputeBalance(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 1, d, hour, 0, 0, 0, time.Local)
+	}
+
+	schedule := config.DefaultSchedule()
+	schedule.DaysOff["2026-01-13"] = config.DayOff{Date: day(13, 0), Description: "holiday"}
+
+	// Thursday January 8 until Tuesday January 13, which is off, with work
+	// on Sunday
+	entries := []*types.Entry{
+		testEntry(9, 8*time.Hour, "main", ""),
+		testEntry(11, time.Hour, "main", ""),
+		testEntry(12, 6*time.Hour, "main", ""),
+	}
+
+	tests := []struct {
+		weekStart time.Weekday
+		expected  []balanceWeek
+	}{
+		{time.Monday, []balanceWeek{
+			{Start: day(5, 0), Expected: 16 * time.Hour, Worked: 9 * time.Hour},
+			{Start: day(12, 0), Expected: 8 * time.Hour, Worked: 6 * time.Hour},
+		}},
+		{time.Sunday, []balanceWeek{
+			{Start: day(4, 0), Expected: 16 * time.Hour, Worked: 8 * time.Hour},
+			{Start: day(11, 0), Expected: 8 * time.Hour, Worked: 7 * time.Hour},
+		}},
+	}
+
+	for _, test := range tests {
+		weeks := computeBalance(schedule, entries, day(8, 15), day(13, 10), test.weekStart)
+		if len(weeks) != len(test.expected) {
+			t.Errorf("with weeks starting on %s there are %d weeks, expected %d", test.weekStart, len(weeks), len(test.expected))
+			continue
+		}
+		for i, week := range weeks {
+			if expected := test.expected[i]; !week.Start.Equal(expected.Start) || week.Expected != expected.Expected || week.Worked != expected.Worked {
+				t.Errorf("with weeks starting on %s week %d is %+v, expected %+v", test.weekStart, i, *week, expected)
+			}
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"got/flag"
+	"io"
 	"strings"
 )
 
 type Command struct {
 	Names       []string
 	Description string
-	Usage       string
-	Fn          func() error
+	// Usage describes the arguments of the command, one line per way to call
+	// it.  The flags are described by Flags.
+	Usage string
+	Flags []flag.Spec
+	Fn    func() error
+	// Hidden commands are not listed in the usage.
+	Hidden bool
 }
 
 func (c *Command) Match(val string) bool {
@@ -19,6 +27,47 @@ func (c *Command) Match(val string) bool {
 	}
 	return false
 }
+
+// HasFlag returns whether the command accepts the flag with the given name.
+func (c *Command) HasFlag(name string) bool {
+	for _, spec := range c.Flags {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFlags returns an error for the first given flag the command does not
+// accept, global flags are accepted by every command.
+func (c *Command) CheckFlags(given []string, global []flag.Spec) error {
+	for _, name := range given {
+		isGlobal := false
+		for _, spec := range global {
+			isGlobal = isGlobal || spec.Name == name
+		}
+
+		if !isGlobal && !c.HasFlag(name) {
+			return fmt.Errorf("flag --%s is not valid for %s", name, c.Names[0])
+		}
+	}
+	return nil
+}
+
+// WriteHelp writes the description, usage and flags of the command.
+func (c *Command) WriteHelp(w io.Writer) {
+	fmt.Fprintf(w, "%s: %s\n", strings.Join(c.Names, ", "), c.Description)
+	for _, line := range strings.Split(c.Usage, "\n") {
+		fmt.Fprintf(w, "\tgot %s %s\n", c.Names[0], line)
+	}
+	if len(c.Flags) > 0 {
+		fmt.Fprintf(w, "\nflags:\n")
+	}
+	for _, spec := range c.Flags {
+		fmt.Fprintf(w, "\t%s\n", spec.Help())
+	}
+}
+
 func (c *Command) MatchPrefix(prefix string) bool {
 	for _, name := range c.Names {
 		if strings.HasPrefix(name, prefix) {
@@ -37,7 +86,7 @@ func MakeManager() *CommandManager {
 	return &CommandManager{}
 }
 
-func (m *CommandManager) AddCommand(names []string, description, usage string, fn func() error) {
+func (m *CommandManager) AddCommand(names []string, description, usage string, flags []flag.Spec, fn func() error) *Command {
 	for _, name := range names {
 		if m.GetByName(name) != nil {
 			panic("command already exist")
@@ -48,9 +97,11 @@ func (m *CommandManager) AddCommand(names []string, description, usage string, f
 		Names:       names,
 		Description: description,
 		Usage:       usage,
+		Flags:       flags,
 		Fn:          fn,
 	}
 	m.commands = append(m.commands, cmd)
+	return cmd
 }
 
 func (m *CommandManager) GetByPrefix(prefix string) []*Command {
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"got/config"
+	"got/flag"
+	"got/formatters"
+	"sort"
+	"strings"
+)
+
+// completeCommand is the hidden command the completion scripts call with the
+// words on the command line to get the candidates for the last word.
+const completeCommand = "__complete"
+
+// maxCompletedIDs is the amount of most recent entries completed for --id.
+const maxCompletedIDs = 20
+
+var completionScripts = map[string]string{
+	"bash": `# bash completion for got, load with: source <(got completion bash)
+_got() {
+	local IFS=$'\n'
+	COMPREPLY=($(got __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -F _got got
+`,
+	"zsh": `#compdef got
+# zsh completion for got, load with: source <(got completion zsh)
+_got() {
+	local -a candidates
+	local line
+	for line in "${(@f)$(got __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+		if [[ $line == *$'\t'* ]]; then
+			candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+		elif [[ -n $line ]]; then
+			candidates+=("${line//:/\\:}")
+		fi
+	done
+	_describe 'got' candidates
+}
+compdef _got got
+`,
+	"fish": `# fish completion for got, load with: got completion fish | source
+function __got_complete
+	set -l words (commandline -opc) (commandline -ct)
+	got __complete -- $words[2..-1] 2>/dev/null
+end
+complete -c got -f -a '(__got_complete)'
+`,
+}
+
+// sheetCommands are the commands that take a sheet as argument.
+var sheetCommands = map[string]bool{
+	"display": true,
+	"report":  true,
+	"stats":   true,
+	"balance": true,
+	"goals":   true,
+	"sheet":   true,
+	"kill":    true,
+	"move":    true,
+	"idle":    true,
+}
+
+// completer produces the completion candidates, a candidate can have a
+// description after a tab.
+type completer struct {
+	state   *State
+	aliases []config.Alias
+}
+
+func (c *completer) sheets() ([]string, error) {
+	return c.state.GetAllSheets()
+}
+
+func (c *completer) ids() ([]string, error) {
+	entries, err := c.state.GetAllEntries("")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for i := len(entries) - 1; i >= 0 && len(res) < maxCompletedIDs; i-- {
+		res = append(res, fmt.Sprintf("%d\t%s: %s", entries[i].ID, entries[i].Sheet, entries[i].Note))
+	}
+	return res, nil
+}
+
+func (c *completer) commands() []string {
+	var res []string
+	for _, cmd := range commands.GetByPrefix("") {
+		if cmd.Hidden {
+			continue
+		}
+		for _, name := range cmd.Names {
+			res = append(res, name+"\t"+cmd.Description)
+		}
+	}
+	for _, alias := range c.aliases {
+		res = append(res, alias.Name+"\t"+alias.Command)
+	}
+	for _, name := range discoverPlugins() {
+		res = append(res, name+"\tplugin")
+	}
+	return res
+}
+
+// flags returns the flags of the command, or all flags when the command is
+// not known.
+func (c *completer) flags(command string) []string {
+	specs := flagSpecs
+	if cmd := commands.GetByName(command); cmd != nil {
+		specs = append(append([]flag.Spec{}, cmd.Flags...), globalFlags...)
+	}
+
+	var res []string
+	for _, spec := range specs {
+		res = append(res, "--"+spec.Name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (c *completer) flagValues(name, cur string) ([]string, error) {
+	switch name {
+	case "formatter":
+		return formatters.Names(), nil
+	case "id":
+		return c.ids()
+	case "sheet":
+		return c.sheets()
+	case "group-by":
+		// complete the last grouping in the comma separated list
+		prefix := ""
+		if i := strings.LastIndex(cur, ","); i >= 0 {
+			prefix = cur[:i+1]
+		}
+
+		var res []string
+		for name := range reportGroupings {
+			res = append(res, prefix+name)
+		}
+		sort.Strings(res)
+		return res, nil
+	}
+	return nil, nil
+}
+
+// Complete returns the candidates for the last word in words, which are the
+// words on the command line after the program name.
+func (c *completer) Complete(words []string) ([]string, error) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+
+	fs := makeFlagSet()
+	command := ""
+	// literal is set after a "--", the words after it are not flags
+	literal := false
+	for i := 0; i < len(words)-1; i++ {
+		word := words[i]
+		if word == "--" && !literal {
+			literal = true
+			continue
+		}
+		if name, _, hasValue, ok := fs.Lookup(word); ok && !literal {
+			if hasValue || fs.IsBool(name) {
+				continue
+			}
+
+			if i == len(words)-2 {
+				// the current word is the value of this flag
+				values, err := c.flagValues(name, cur)
+				return filterCandidates(values, cur), err
+			}
+			i++
+			continue
+		}
+
+		if command == "" {
+			command = word
+		}
+	}
+
+	var candidates []string
+	var err error
+	if name, value, hasValue, ok := fs.Lookup(cur); ok && hasValue && !literal {
+		// the value of a --name=value flag
+		prefix := strings.TrimSuffix(cur, value)
+		values, err := c.flagValues(name, value)
+		for _, value := range filterCandidates(values, value) {
+			candidates = append(candidates, prefix+value)
+		}
+		return candidates, err
+	} else if strings.HasPrefix(cur, "-") && !literal {
+		candidates = c.flags(command)
+	} else if command == "" {
+		candidates = c.commands()
+	} else if cmd := commands.GetByName(command); cmd != nil && sheetCommands[cmd.Names[0]] {
+		candidates, err = c.sheets()
+	}
+
+	return filterCandidates(candidates, cur), err
+}
+
+func filterCandidates(candidates []string, prefix string) []string {
+	var res []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			res = append(res, candidate)
+		}
+	}
+	return res
+}
+
+// completionWords returns the words given to the hidden complete command, they
+// are passed after a "--" so they are not parsed as flags.
+func completionWords(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[i+1:]
+		}
+	}
+	return nil
+}
+
+func writeCandidates(candidates []string) {
+	for _, candidate := range candidates {
+		fmt.Println(candidate)
+	}
+}
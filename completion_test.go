@@ -0,0 +1,90 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComplete(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	if err := state.AddEntries([]*types.Entry{
+		{Start: start, End: &end, Sheet: "main", Note: "first"},
+		{Start: end, Sheet: "work", Note: "second"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	registered := commands
+	commands = MakeManager()
+	defer func() {
+		commands = registered
+	}()
+	commands.AddCommand([]string{"display"}, "show entries", "", flagsNamed("id", "filter", "formatter", "formatter-opt"), nil)
+	commands.AddCommand([]string{"report"}, "show a report", "", flagsNamed("group-by"), nil)
+	commands.AddCommand([]string{"kill"}, "delete entries", "", flagsNamed("id", "dry-run"), nil)
+	commands.AddCommand([]string{completeCommand}, "complete", "", nil, nil).Hidden = true
+
+	// a plugin in $PATH
+	dir, err := ioutil.TempDir("", "got")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(path.Join(dir, pluginPrefix+"sync"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", oldPath)
+
+	c := &completer{
+		state:   state,
+		aliases: []config.Alias{{Name: "standup", Command: "display --filter standup"}},
+	}
+
+	tests := []struct {
+		words    []string
+		expected []string
+	}{
+		{nil, []string{"display", "report", "kill", "standup", "sync"}},
+		{[]string{"di"}, []string{"display"}},
+		{[]string{"st"}, []string{"standup"}},
+		{[]string{"display", "--f"}, []string{"--filter", "--formatter", "--formatter-opt"}},
+		{[]string{"kill", "--"}, []string{"--dry-run", "--id", "--no", "--output", "--tz", "--yes"}},
+		{[]string{"display", "--formatter", "c"}, []string{"csv"}},
+		{[]string{"display", "--formatter=j"}, []string{"--formatter=json"}},
+		{[]string{"display", "--id", ""}, []string{"2", "1"}},
+		{[]string{"report", "--group-by", "day,sh"}, []string{"day,sheet"}},
+		{[]string{"display", "w"}, []string{"work"}},
+		{[]string{"kill", "--yes", ""}, []string{"main", "work"}},
+		{[]string{"--filter", "x", "display", "m"}, []string{"main"}},
+		// the words after "--" are not flags
+		{[]string{"display", "--", "--f"}, nil},
+	}
+
+	for _, test := range tests {
+		candidates, err := c.Complete(test.words)
+		if err != nil {
+			t.Errorf("completing %q: %s", test.words, err)
+			continue
+		}
+
+		var names []string
+		for _, candidate := range candidates {
+			names = append(names, strings.SplitN(candidate, "\t", 2)[0])
+		}
+		if strings.Join(names, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("the candidates for %q are %q, expected %q", test.words, names, test.expected)
+		}
+	}
+}
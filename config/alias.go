@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alias is a name that expands to a command with its flags.
+type Alias struct {
+	Name    string
+	Command string
+}
+
+// LoadAliases reads the aliases file from the config directory.  Every line
+// contains the name of the alias and what it expands to, eg.
+// "standup: display --start yesterday --formatter json".
+func LoadAliases() ([]Alias, error) {
+	lines, err := readLines("aliases")
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Alias
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid alias line \"%s\"", line)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		command := strings.TrimSpace(parts[1])
+		if name == "" || strings.ContainsAny(name, " \t") || strings.HasPrefix(name, "-") {
+			return nil, fmt.Errorf("invalid alias name \"%s\"", name)
+		} else if command == "" {
+			return nil, fmt.Errorf("alias %s is empty", name)
+		} else if seen[name] {
+			return nil, fmt.Errorf("alias %s is defined more than once", name)
+		}
+		seen[name] = true
+
+		res = append(res, Alias{Name: name, Command: command})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,411 @@
+package config
+
+import (
+	"fmt"
+	"got/formatters"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings from ~/.timetrap.yml, overridden by the settings
+// from config.yml in the config directory.  The keys are the same as the ones
+// timetrap uses, where they exist in timetrap.
+type Config struct {
+	DefaultFormatter string
+	// AutoCheckout stops the running entry when a new one is started.
+	AutoCheckout bool
+	RequireNote  bool
+	// RoundInSeconds is the duration to round to when rounding is asked for.
+	RoundInSeconds time.Duration
+	// RoundByDefault rounds durations without --round.
+	RoundByDefault bool
+	RoundMode      string
+	RoundPerDay    bool
+	WeekStart      time.Weekday
+	DatabaseFile   string
+	NoteEditor     string
+	// Timezone is the name of the time zone times are displayed and entered
+	// in, empty for the zone of the system.
+	Timezone string
+
+	// MaxRunning is the duration after which a running entry is considered
+	// forgotten, zero disables the warning.
+	MaxRunning time.Duration
+	// LastActivityHook is a shell command that prints the time of the last
+	// activity of the user, used by `out --at-last-activity`.
+	LastActivityHook string
+}
+
+func Default() *Config {
+	return &Config{
+		DefaultFormatter: "human",
+		RoundInSeconds:   15 * time.Minute,
+		RoundMode:        "nearest",
+		WeekStart:        time.Monday,
+		MaxRunning:       10 * time.Hour,
+	}
+}
+
+// Option is a setting in the config file.
+type Option struct {
+	Key         string
+	Description string
+
+	set func(c *Config, value string) error
+	get func(c *Config) string
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %s", value)
+}
+
+// setBool sets the option to the boolean value, the option is left as it is
+// when the value is invalid.
+func setBool(option *bool, value string) error {
+	b, err := parseBool(value)
+	if err != nil {
+		return err
+	}
+	*option = b
+	return nil
+}
+
+// Options are all settings, in the order they are listed.
+var Options = []Option{
+	{
+		Key:         "default_formatter",
+		Description: "the formatter to use when --formatter is not given",
+		set: func(c *Config, value string) error {
+			if formatters.Get(value) == nil {
+				return fmt.Errorf("unknown formatter %s, can be %s", value, strings.Join(formatters.Names(), ", "))
+			}
+			c.DefaultFormatter = value
+			return nil
+		},
+		get: func(c *Config) string { return c.DefaultFormatter },
+	},
+	{
+		Key:         "auto_checkout",
+		Description: "stop the running entry when starting a new one",
+		set: func(c *Config, value string) error {
+			return setBool(&c.AutoCheckout, value)
+		},
+		get: func(c *Config) string { return strconv.FormatBool(c.AutoCheckout) },
+	},
+	{
+		Key:         "require_note",
+		Description: "refuse to start entries without a note",
+		set: func(c *Config, value string) error {
+			return setBool(&c.RequireNote, value)
+		},
+		get: func(c *Config) string { return strconv.FormatBool(c.RequireNote) },
+	},
+	{
+		Key:         "round_in_seconds",
+		Description: "the amount of seconds to round durations to when rounding",
+		set: func(c *Config, value string) error {
+			seconds, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid amount of seconds %s", value)
+			}
+			c.RoundInSeconds = time.Duration(seconds) * time.Second
+			return nil
+		},
+		get: func(c *Config) string { return strconv.Itoa(int(c.RoundInSeconds / time.Second)) },
+	},
+	{
+		Key:         "round_by_default",
+		Description: "round durations to round_in_seconds without --round",
+		set: func(c *Config, value string) error {
+			return setBool(&c.RoundByDefault, value)
+		},
+		get: func(c *Config) string { return strconv.FormatBool(c.RoundByDefault) },
+	},
+	{
+		Key:         "round_mode",
+		Description: "how to round durations, 'nearest', 'up' or 'down'",
+		set: func(c *Config, value string) error {
+			switch value {
+			case "nearest", "up", "down":
+				c.RoundMode = value
+				return nil
+			}
+			return fmt.Errorf("invalid rounding mode %s", value)
+		},
+		get: func(c *Config) string { return c.RoundMode },
+	},
+	{
+		Key:         "round_per_day",
+		Description: "round the total of every day instead of every entry",
+		set: func(c *Config, value string) error {
+			return setBool(&c.RoundPerDay, value)
+		},
+		get: func(c *Config) string { return strconv.FormatBool(c.RoundPerDay) },
+	},
+	{
+		Key:         "week_start",
+		Description: "the day weeks start on",
+		set: func(c *Config, value string) error {
+			day, err := parseWeekday(value)
+			if err != nil {
+				return err
+			}
+			c.WeekStart = day
+			return nil
+		},
+		get: func(c *Config) string { return c.WeekStart.String() },
+	},
+	{
+		Key:         "database_file",
+		Description: "the path of the database",
+		set: func(c *Config, value string) error {
+			c.DatabaseFile = value
+			return nil
+		},
+		get: func(c *Config) string { return c.DatabaseFile },
+	},
+	{
+		Key:         "note_editor",
+		Description: "the editor to edit entries in, instead of $VISUAL or $EDITOR",
+		set: func(c *Config, value string) error {
+			// timetrap uses false for no editor
+			if value == "false" {
+				value = ""
+			}
+			c.NoteEditor = value
+			return nil
+		},
+		get: func(c *Config) string { return c.NoteEditor },
+	},
+	{
+		Key:         "timezone",
+		Description: "the time zone to show and enter times in, like 'Europe/Amsterdam', instead of the zone of the system",
+		set: func(c *Config, value string) error {
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("unknown time zone %s", value)
+			}
+			c.Timezone = value
+			return nil
+		},
+		get: func(c *Config) string { return c.Timezone },
+	},
+	{
+		Key:         "max_running",
+		Description: "the duration after which a running entry is considered forgotten, 0 disables the warning",
+		set: func(c *Config, value string) error {
+			max, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			c.MaxRunning = max
+			return nil
+		},
+		get: func(c *Config) string { return c.MaxRunning.String() },
+	},
+	{
+		Key:         "last_activity_hook",
+		Description: "a shell command printing the time of your last activity, used by out --at-last-activity",
+		set: func(c *Config, value string) error {
+			c.LastActivityHook = value
+			return nil
+		},
+		get: func(c *Config) string { return c.LastActivityHook },
+	},
+}
+
+// GetOption returns the option with the given key, or nil if there is none.
+func GetOption(key string) *Option {
+	for i := range Options {
+		if Options[i].Key == key {
+			return &Options[i]
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the option with the given key as it would be
+// written in the config file.
+func (c *Config) Get(key string) (string, error) {
+	option := GetOption(key)
+	if option == nil {
+		return "", fmt.Errorf("unknown config key %s", key)
+	}
+	return option.get(c), nil
+}
+
+// Set parses the value for the option with the given key.
+func (c *Config) Set(key, value string) error {
+	option := GetOption(key)
+	if option == nil {
+		return fmt.Errorf("unknown config key %s", key)
+	} else if err := option.set(c, value); err != nil {
+		return fmt.Errorf("%s: %s", key, err)
+	}
+	return nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// quote quotes the value if it would not be read back as is otherwise.
+func quote(value string) string {
+	if value == "" || strings.TrimSpace(value) != value || strings.ContainsAny(value, "#'\"") {
+		return "'" + value + "'"
+	}
+	return value
+}
+
+// parseLine parses a "key: value" line, the simple subset of YAML that is
+// needed for the config files.  ok is false for lines that are not a key and
+// a value, like the document start and list items.
+func parseLine(line string) (key, value string, ok bool) {
+	if strings.HasPrefix(line, "-") {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(parts[0])
+	value = strings.TrimSpace(parts[1])
+	if unquoted := unquote(value); unquoted != value {
+		value = unquoted
+	} else if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return key, value, true
+}
+
+// apply sets the options from the lines of a config file, unknown keys and
+// invalid values are an error unless lenient is set.  The valid lines are set
+// either way and the first error is returned.  The config file of
+// timetrap is read leniently, since it can have values only timetrap
+// supports, like the ical formatter.
+func (c *Config) apply(lines []string, lenient bool) error {
+	var res error
+	for _, line := range lines {
+		key, value, ok := parseLine(line)
+		if !ok {
+			if !lenient && res == nil {
+				res = fmt.Errorf("invalid config line \"%s\"", line)
+			}
+			continue
+		}
+
+		if GetOption(key) == nil && lenient {
+			continue
+		}
+		if err := c.Set(key, value); err != nil && !lenient && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// TimetrapPath returns the path of the config file of timetrap.
+func TimetrapPath() (string, error) {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(homedir, ".timetrap.yml"), nil
+}
+
+// Load reads ~/.timetrap.yml and config.yml from the config directory,
+// settings that are in neither file keep their default value.  Keys in the
+// timetrap file that got does not know are ignored.  When config.yml has
+// errors the config is returned with its valid settings as well, so the config
+// command can still use it.
+func Load() (*Config, error) {
+	c := Default()
+
+	fname, err := TimetrapPath()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := readFileLines(fname)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.apply(lines, true); err != nil {
+		return nil, fmt.Errorf("%s: %s", fname, err)
+	}
+
+	lines, err = readLines("config.yml")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.apply(lines, false); err != nil {
+		return c, fmt.Errorf("config.yml: %s", err)
+	}
+
+	return c, nil
+}
+
+// Write sets the option with the given key to the value in config.yml in the
+// config directory, keeping the other lines as they are.
+func Write(key, value string) error {
+	if err := Default().Set(key, value); err != nil {
+		return err
+	}
+
+	fname, err := Path("config.yml")
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(fname)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	newLine := fmt.Sprintf("%s: %s", key, quote(value))
+	var lines []string
+	found := false
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		if k, _, ok := parseLine(strings.TrimSpace(line)); ok && k == key {
+			line = newLine
+			found = true
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if !found {
+		lines = append(lines, newLine)
+	}
+
+	if err := os.MkdirAll(path.Dir(fname), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fname, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// ExpandHome replaces a leading ~ in the path by the home directory.
+func ExpandHome(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(homedir, p[1:]), nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetDefaultFormatter(t *testing.T) {
+	c := Default()
+	for _, value := range []string{"csv", "text", "human"} {
+		if err := c.Set("default_formatter", value); err != nil {
+			t.Errorf("setting default_formatter to %s: %s", value, err)
+		}
+	}
+
+	if err := c.Set("default_formatter", "bogus"); err == nil {
+		t.Errorf("setting default_formatter to bogus gave no error")
+	} else if c.DefaultFormatter != "human" {
+		t.Errorf("default_formatter is %s after setting it to bogus, expected human", c.DefaultFormatter)
+	}
+}
+
+func TestApply(t *testing.T) {
+	lines := []string{"default_formatter: ical", "auto_checkout: true", "formatter_search_paths:"}
+
+	c := Default()
+	if err := c.apply(lines, true); err != nil {
+		t.Fatalf("applying leniently: %s", err)
+	}
+	if c.DefaultFormatter != "human" || !c.AutoCheckout {
+		t.Errorf("the config is %+v, expected the human formatter and auto_checkout", c)
+	}
+
+	if err := Default().apply(lines, false); err == nil {
+		t.Errorf("applying strictly gave no error")
+	}
+}
+
+func TestApplyKeepsValidLines(t *testing.T) {
+	lines := []string{"round_mode: sideways", "auto_checkout: true", "bogus", "week_start: sunday"}
+
+	c := Default()
+	if err := c.apply(lines, false); err == nil || !strings.Contains(err.Error(), "sideways") {
+		t.Errorf("applying strictly gave the error %v, expected the one of round_mode", err)
+	}
+	if !c.AutoCheckout || c.WeekStart != time.Sunday {
+		t.Errorf("the config is %+v, expected the valid lines to be set", c)
+	}
+}
+
+func TestSetInvalidKeepsValue(t *testing.T) {
+	c := Default()
+	for _, line := range []string{"auto_checkout: yes", "require_note: yes", "round_by_default: yes", "round_per_day: yes", "week_start: sunday", "max_running: 2h"} {
+		key, value, _ := parseLine(line)
+		if err := c.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, key := range []string{"auto_checkout", "require_note", "round_by_default", "round_per_day", "week_start", "max_running"} {
+		before, _ := c.Get(key)
+		if err := c.Set(key, "bogus"); err == nil {
+			t.Errorf("setting %s to bogus gave no error", key)
+		}
+		if after, _ := c.Get(key); after != before {
+			t.Errorf("%s is %s after setting it to bogus, expected %s", key, after, before)
+		}
+	}
+}
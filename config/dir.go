@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path"
+)
+
+// Dir returns the directory got reads its configuration files from, this is
+// $XDG_CONFIG_HOME/got or ~/.config/got.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return path.Join(dir, "got"), nil
+	}
+
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(homedir, ".config", "got"), nil
+}
+
+// Path returns the path of the file with the given name in the config
+// directory.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, name), nil
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DayOff is a public holiday or leave day, a zero Duration means the whole
+// day is off.
+type DayOff struct {
+	Date        time.Time
+	Duration    time.Duration
+	Description string
+}
+
+// WorkingHours is the part of a day that is worked, as offsets from the start
+// of the day.
+type WorkingHours struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// Schedule is the amount of time that is expected to be worked on every day
+// of the week and the hours in which that happens, together with the days that
+// are (partially) off.
+type Schedule struct {
+	Days    [7]time.Duration
+	Hours   [7]*WorkingHours
+	DaysOff map[string]DayOff
+}
+
+// DefaultSchedule is used when there is no schedule file, it expects 8 hours
+// between 9:00 and 17:00 on every weekday.
+func DefaultSchedule() *Schedule {
+	s := &Schedule{
+		DaysOff: make(map[string]DayOff),
+	}
+	for day := time.Monday; day <= time.Friday; day++ {
+		s.Days[day] = 8 * time.Hour
+		s.Hours[day] = &WorkingHours{From: 9 * time.Hour, To: 17 * time.Hour}
+	}
+	return s
+}
+
+// WorkingHours returns the start and end of the working hours on the given
+// day, ok is false when there are no working hours on that day.
+func (s *Schedule) WorkingHours(day time.Time) (from, to time.Time, ok bool) {
+	hours := s.Hours[day.Weekday()]
+	if hours == nil || s.Expected(day) == 0 {
+		return from, to, false
+	}
+
+	y, m, d := day.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	return midnight.Add(hours.From), midnight.Add(hours.To), true
+}
+
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Expected returns the time that should be worked on the given day.
+func (s *Schedule) Expected(day time.Time) time.Duration {
+	expected := s.Days[day.Weekday()]
+
+	off, has := s.DaysOff[dateKey(day)]
+	if !has {
+		return expected
+	} else if off.Duration == 0 || off.Duration >= expected {
+		return 0
+	}
+	return expected - off.Duration
+}
+
+// readLines returns the non empty lines of the file with the given name in
+// the config directory, without the lines starting with '#'.  A missing file
+// has no lines.
+func readLines(name string) ([]string, error) {
+	fname, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return readFileLines(fname)
+}
+
+// readFileLines is like readLines, but for the file at the given path.
+func readFileLines(fname string) ([]string, error) {
+	f, err := os.Open(fname)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && line[0] != '#' {
+			res = append(res, line)
+		}
+	}
+	return res, scanner.Err()
+}
+
+func parseWeekday(str string) (time.Weekday, error) {
+	str = strings.ToLower(str)
+	if len(str) >= 3 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			name := strings.ToLower(day.String())
+			if strings.HasPrefix(name, str) {
+				return day, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %s", str)
+}
+
+func parseTimeOfDay(str string) (time.Duration, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseWorkingHours parses a range of times like "09:00-17:30".
+func parseWorkingHours(str string) (*WorkingHours, error) {
+	parts := strings.SplitN(str, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid working hours %s", str)
+	}
+
+	from, err := parseTimeOfDay(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeOfDay(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if to <= from {
+		return nil, fmt.Errorf("invalid working hours %s", str)
+	}
+
+	return &WorkingHours{From: from, To: to}, nil
+}
+
+// parseWeekdays parses a weekday or a range of weekdays like "mon-thu".
+func parseWeekdays(str string) ([]time.Weekday, error) {
+	parts := strings.SplitN(str, "-", 2)
+
+	first, err := parseWeekday(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	last := first
+	if len(parts) == 2 {
+		if last, err = parseWeekday(parts[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	res := []time.Weekday{first}
+	for day := first; day != last; {
+		day = (day + 1) % 7
+		res = append(res, day)
+	}
+	return res, nil
+}
+
+// LoadSchedule reads the schedule file and the holidays file from the config
+// directory.
+//
+// Every line of the schedule file contains a weekday or a range of weekdays,
+// the time to work on those days and optionally the working hours, eg.
+// "mon-thu 8h 08:30-17:00" or "fri 6h".  The working hours default to 9:00
+// until the expected time has been worked.  If the file does not exist the
+// DefaultSchedule is used.
+//
+// Every line of the holidays file contains a date, optionally the time off on
+// that day (the whole day if omitted) and a description, eg.
+// "2026-12-25 christmas" or "2026-07-03 4h dentist".
+func LoadSchedule() (*Schedule, error) {
+	lines, err := readLines("schedule")
+	if err != nil {
+		return nil, err
+	}
+
+	s := DefaultSchedule()
+	if len(lines) > 0 {
+		s.Days = [7]time.Duration{}
+		s.Hours = [7]*WorkingHours{}
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid schedule line \"%s\"", line)
+		}
+
+		days, err := parseWeekdays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		duration, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		hours := &WorkingHours{From: 9 * time.Hour, To: 9*time.Hour + duration}
+		if len(fields) > 2 {
+			if hours, err = parseWorkingHours(fields[2]); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, day := range days {
+			s.Days[day] = duration
+			s.Hours[day] = hours
+		}
+	}
+
+	lines, err = readLines("holidays")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+
+		date, err := time.ParseInLocation("2006-01-02", fields[0], time.Local)
+		if err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
+
+		off := DayOff{Date: date}
+		if len(fields) > 0 {
+			if duration, err := time.ParseDuration(fields[0]); err == nil {
+				off.Duration = duration
+				fields = fields[1:]
+			}
+		}
+		off.Description = strings.Join(fields, " ")
+
+		s.DaysOff[dateKey(date)] = off
+	}
+
+	return s, nil
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// withConfigFiles makes a config directory with the given files and uses it
+// like $XDG_CONFIG_HOME does, the returned function removes it.
+func withConfigFiles(t *testing.T, files map[string]string) func() {
+	dir, err := ioutil.TempDir("", "got")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove := func() {
+		os.RemoveAll(dir)
+	}
+
+	if err := os.Mkdir(path.Join(dir, "got"), 0755); err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(path.Join(dir, "got", name), []byte(content), 0644); err != nil {
+			remove()
+			t.Fatal(err)
+		}
+	}
+
+	old, had := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	return func() {
+		if had {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+		remove()
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected []time.Weekday
+	}{
+		{"mon", []time.Weekday{time.Monday}},
+		{"Tuesday", []time.Weekday{time.Tuesday}},
+		{"mon-thu", []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday}},
+		// ranges wrap around the end of the week
+		{"fri-mon", []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}},
+		{"sun-sun", []time.Weekday{time.Sunday}},
+	}
+
+	for _, test := range tests {
+		days, err := parseWeekdays(test.str)
+		if err != nil {
+			t.Errorf("parseWeekdays(%s): %s", test.str, err)
+			continue
+		}
+		if len(days) != len(test.expected) {
+			t.Errorf("parseWeekdays(%s) = %v, expected %v", test.str, days, test.expected)
+			continue
+		}
+		for i := range days {
+			if days[i] != test.expected[i] {
+				t.Errorf("parseWeekdays(%s) = %v, expected %v", test.str, days, test.expected)
+				break
+			}
+		}
+	}
+
+	for _, str := range []string{"", "mo", "bogus", "mon-", "mon-xyz", "-fri"} {
+		if days, err := parseWeekdays(str); err == nil {
+			t.Errorf("parseWeekdays(%s) = %v, expected an error", str, days)
+		}
+	}
+}
+
+func TestLoadSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		days     [7]time.Duration
+		// hours are the working hours on Monday and Friday
+		monday, friday WorkingHours
+	}{
+		// the default schedule
+		{
+			"",
+			[7]time.Duration{0, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 0},
+			WorkingHours{9 * time.Hour, 17 * time.Hour},
+			WorkingHours{9 * time.Hour, 17 * time.Hour},
+		},
+		{
+			"# four long days\nmon-thu 8h 08:30-17:00\nfri 6h\n",
+			[7]time.Duration{0, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 8 * time.Hour, 6 * time.Hour, 0},
+			WorkingHours{8*time.Hour + 30*time.Minute, 17 * time.Hour},
+			WorkingHours{9 * time.Hour, 15 * time.Hour},
+		},
+		{
+			"fri-mon 4h\n",
+			[7]time.Duration{4 * time.Hour, 4 * time.Hour, 0, 0, 0, 4 * time.Hour, 4 * time.Hour},
+			WorkingHours{9 * time.Hour, 13 * time.Hour},
+			WorkingHours{9 * time.Hour, 13 * time.Hour},
+		},
+	}
+
+	for _, test := range tests {
+		restore := withConfigFiles(t, map[string]string{"schedule": test.schedule})
+		s, err := LoadSchedule()
+		restore()
+		if err != nil {
+			t.Errorf("loading the schedule %q: %s", test.schedule, err)
+			continue
+		}
+
+		if s.Days != test.days {
+			t.Errorf("the schedule %q has the days %v, expected %v", test.schedule, s.Days, test.days)
+		}
+		if s.Hours[time.Monday] == nil || *s.Hours[time.Monday] != test.monday {
+			t.Errorf("the schedule %q has the hours %v on Monday, expected %v", test.schedule, s.Hours[time.Monday], test.monday)
+		}
+		if s.Hours[time.Friday] == nil || *s.Hours[time.Friday] != test.friday {
+			t.Errorf("the schedule %q has the hours %v on Friday, expected %v", test.schedule, s.Hours[time.Friday], test.friday)
+		}
+	}
+
+	for _, schedule := range []string{
+		"mon\n",
+		"mo 8h\n",
+		"mon eight\n",
+		"mon 8h 9-17\n",
+		"mon 8h 17:00-09:00\n",
+		"mon-bogus 8h\n",
+	} {
+		restore := withConfigFiles(t, map[string]string{"schedule": schedule})
+		if _, err := LoadSchedule(); err == nil {
+			t.Errorf("loading the invalid schedule %q gave no error", schedule)
+		}
+		restore()
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	restore := withConfigFiles(t, map[string]string{
+		"holidays": "2026-12-25 christmas\n2026-07-03 4h dentist\n",
+	})
+	defer restore()
+
+	s, err := LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		day      time.Time
+		expected time.Duration
+	}{
+		{time.Date(2026, 12, 25, 12, 0, 0, 0, time.Local), 0},
+		{time.Date(2026, 7, 3, 12, 0, 0, 0, time.Local), 4 * time.Hour},
+		{time.Date(2026, 7, 2, 12, 0, 0, 0, time.Local), 8 * time.Hour},
+		{time.Date(2026, 7, 4, 12, 0, 0, 0, time.Local), 0},
+	}
+	for _, test := range tests {
+		if expected := s.Expected(test.day); expected != test.expected {
+			t.Errorf("%s is expected to be worked on %s, expected %s", expected, test.day.Format("2006-01-02"), test.expected)
+		}
+	}
+	if off := s.DaysOff["2026-07-03"]; off.Description != "dentist" {
+		t.Errorf("the day off on 2026-07-03 is %q, expected dentist", off.Description)
+	}
+
+	restoreInvalid := withConfigFiles(t, map[string]string{"holidays": "25-12-2026 christmas\n"})
+	defer restoreInvalid()
+	if _, err := LoadSchedule(); err == nil {
+		t.Errorf("loading a holiday with an invalid date gave no error")
+	}
+}
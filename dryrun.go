@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"got/types"
+	"got/utils"
+	"io"
+	"strings"
+	"time"
+)
+
+// snapshot is the part of the state of which a dry run shows the changes.
+type snapshot struct {
+	entries []*types.Entry
+	meta    *Meta
+	goals   []*types.Goal
+	// plannedEnd is the planned end of the running entry.
+	plannedEnd *time.Time
+}
+
+func takeSnapshot(state *State) (*snapshot, error) {
+	var res snapshot
+	var err error
+
+	if res.entries, err = state.GetAllEntries(""); err != nil {
+		return nil, err
+	}
+	if res.meta, err = state.GetMeta(); err != nil {
+		return nil, err
+	}
+	if res.goals, err = state.GetAllGoals(); err != nil {
+		return nil, err
+	}
+
+	running, err := state.GetCurrentEntry()
+	if err != nil {
+		return nil, err
+	} else if running != nil {
+		if res.plannedEnd, err = state.GetPlannedEnd(running.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
+
+// changedEntries returns the entries that are changed, added or removed from
+// before to after, as they were before and as they are after.
+func changedEntries(before, after []*types.Entry) (old, changed []*types.Entry) {
+	byID := make(map[uint64]*types.Entry)
+	for _, entry := range before {
+		byID[entry.ID] = entry
+	}
+
+	kept := make(map[uint64]bool)
+	for _, entry := range after {
+		original, has := byID[entry.ID]
+		if has && formatEditorEntry(original) == formatEditorEntry(entry) {
+			kept[entry.ID] = true
+			continue
+		}
+		changed = append(changed, entry)
+	}
+
+	for _, entry := range before {
+		if !kept[entry.ID] {
+			old = append(old, entry)
+		}
+	}
+	return old, changed
+}
+
+func formatGoals(goal *types.Goal) string {
+	if goal == nil {
+		return "none"
+	}
+	return fmt.Sprintf(
+		"%s per day, %s per week",
+		utils.FormatDuration(goal.Daily),
+		utils.FormatDuration(goal.Weekly),
+	)
+}
+
+func formatPlannedEnd(end *time.Time) string {
+	if end == nil {
+		return "none"
+	}
+	return end.Local().Format("2006-01-02 15:04:05")
+}
+
+func sheetNames(entries []*types.Entry) string {
+	var res []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !seen[entry.Sheet] {
+			seen[entry.Sheet] = true
+			res = append(res, entry.Sheet)
+		}
+	}
+	return strings.Join(res, ", ")
+}
+
+func writeDryRunEntries(w, stderr io.Writer, title string, entries []*types.Entry, formatter types.Formatter, rounding *types.Rounding) error {
+	if len(entries) == 0 {
+		fmt.Fprintf(stderr, "%s: none\n", title)
+		return nil
+	}
+
+	fmt.Fprintf(stderr, "%s:\n", title)
+	return formatter.Write(w, &types.FormatterInput{
+		Sheet:    sheetNames(entries),
+		Entries:  entries,
+		Rounding: rounding,
+	})
+}
+
+// writeDryRun writes the changed entries as they were before and as they are
+// after with the formatter to w, and the other changes to stderr.
+func writeDryRun(w, stderr io.Writer, before, after *snapshot, formatter types.Formatter, rounding *types.Rounding) error {
+	old, changed := changedEntries(before.entries, after.entries)
+
+	if len(old) > 0 || len(changed) > 0 {
+		if err := writeDryRunEntries(w, stderr, "Before", old, formatter, rounding); err != nil {
+			return err
+		}
+		if err := writeDryRunEntries(w, stderr, "After", changed, formatter, rounding); err != nil {
+			return err
+		}
+	}
+
+	if before.meta.CurrentSheet != after.meta.CurrentSheet {
+		fmt.Fprintf(stderr, "Current sheet: %s -> %s\n", before.meta.CurrentSheet, after.meta.CurrentSheet)
+	}
+
+	goals := make(map[string]*types.Goal)
+	for _, goal := range before.goals {
+		goals[goal.Sheet] = goal
+	}
+	for _, goal := range after.goals {
+		if old := formatGoals(goals[goal.Sheet]); old != formatGoals(goal) {
+			fmt.Fprintf(stderr, "Goals of sheet \"%s\": %s -> %s\n", goal.Sheet, old, formatGoals(goal))
+		}
+		delete(goals, goal.Sheet)
+	}
+	for _, goal := range goals {
+		fmt.Fprintf(stderr, "Goals of sheet \"%s\": %s -> none\n", goal.Sheet, formatGoals(goal))
+	}
+
+	if formatPlannedEnd(before.plannedEnd) != formatPlannedEnd(after.plannedEnd) {
+		fmt.Fprintf(
+			stderr,
+			"Planned end: %s -> %s\n",
+			formatPlannedEnd(before.plannedEnd),
+			formatPlannedEnd(after.plannedEnd),
+		)
+	}
+
+	fmt.Fprintln(stderr, "Dry run, nothing was changed.")
+	return nil
+}
+
+// checkFormatterFlags returns an error when --formatter or --formatter-opt is
+// given without --dry-run to a command that changes entries, since such
+// commands only write entries in a dry run.
+func checkFormatterFlags(cmd *Command, given []string, dryRun bool) error {
+	if !cmd.HasFlag("dry-run") || dryRun {
+		return nil
+	}
+	for _, name := range given {
+		if name == "formatter" || name == "formatter-opt" {
+			return fmt.Errorf("flag --%s is only valid for %s with --dry-run", name, cmd.Names[0])
+		}
+	}
+	return nil
+}
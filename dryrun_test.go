@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"got/formatters"
+	"got/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangedEntries(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	entry := func(id uint64, note string) *types.Entry {
+		return &types.Entry{ID: id, Start: start, End: &end, Sheet: "main", Note: note}
+	}
+
+	before := []*types.Entry{entry(1, "kept"), entry(2, "changed"), entry(3, "removed")}
+	after := []*types.Entry{entry(1, "kept"), entry(2, "changed later"), entry(4, "added")}
+
+	old, changed := changedEntries(before, after)
+	if len(old) != 2 || old[0].ID != 2 || old[1].ID != 3 {
+		t.Errorf("the old entries are %v, expected 2 and 3", old)
+	}
+	if len(changed) != 2 || changed[0].Note != "changed later" || changed[1].ID != 4 {
+		t.Errorf("the changed entries are %v, expected 2 and 4", changed)
+	}
+
+	if old, changed := changedEntries(before, before); len(old) != 0 || len(changed) != 0 {
+		t.Errorf("the same entries gave the changes %v and %v", old, changed)
+	}
+}
+
+func TestWriteDryRun(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	planned := start.Add(2 * time.Hour)
+
+	formatter, err := formatters.New("csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := &snapshot{
+		entries: []*types.Entry{{ID: 1, Start: start, Sheet: "main", Note: "running"}},
+		meta:    &Meta{CurrentSheet: "main"},
+		goals:   []*types.Goal{{Sheet: "main", Daily: 8 * time.Hour}},
+	}
+	after := &snapshot{
+		entries:    []*types.Entry{{ID: 1, Start: start, End: &end, Sheet: "main", Note: "running"}},
+		meta:       &Meta{CurrentSheet: "work"},
+		plannedEnd: &planned,
+	}
+
+	var w, stderr bytes.Buffer
+	if err := writeDryRun(&w, &stderr, before, after, formatter, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the duration of the running entry is up to now
+	if lines := strings.Split(strings.TrimSpace(w.String()), "\n"); len(lines) != 4 ||
+		!strings.HasPrefix(lines[1], "1,main,2026-01-05T09:00:00Z,,") ||
+		lines[3] != "1,main,2026-01-05T09:00:00Z,2026-01-05T10:00:00Z,1:00:00,running" {
+		t.Errorf("the entries are written as %q, expected the running and the stopped entry", w.String())
+	}
+	for _, expected := range []string{
+		"Before:\nAfter:\n",
+		"Current sheet: main -> work\n",
+		"Goals of sheet \"main\": 8:00:00 per day, 0:00:00 per week -> none\n",
+		"Planned end: none -> ",
+		"Dry run, nothing was changed.\n",
+	} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("the changes %q don't contain %q", stderr.String(), expected)
+		}
+	}
+
+	w.Reset()
+	stderr.Reset()
+	if err := writeDryRun(&w, &stderr, before, before, formatter, nil); err != nil {
+		t.Fatal(err)
+	}
+	if w.Len() != 0 || stderr.String() != "Dry run, nothing was changed.\n" {
+		t.Errorf("no changes are written as %q and %q", w.String(), stderr.String())
+	}
+}
+
+func TestCheckFormatterFlags(t *testing.T) {
+	kill := &Command{Names: []string{"kill"}, Flags: flagsNamed("id", "dry-run", "formatter", "formatter-opt")}
+	display := &Command{Names: []string{"display"}, Flags: flagsNamed("id", "formatter", "formatter-opt")}
+
+	tests := []struct {
+		cmd    *Command
+		given  []string
+		dryRun bool
+		valid  bool
+	}{
+		{kill, []string{"id"}, false, true},
+		{kill, []string{"formatter"}, false, false},
+		{kill, []string{"id", "formatter-opt"}, false, false},
+		{kill, []string{"dry-run", "formatter", "formatter-opt"}, true, true},
+		{display, []string{"formatter"}, false, true},
+	}
+
+	for _, test := range tests {
+		if err := checkFormatterFlags(test.cmd, test.given, test.dryRun); (err == nil) != test.valid {
+			t.Errorf("checkFormatterFlags(%s, %v, %v) gave %v, expected valid to be %v", test.cmd.Names[0], test.given, test.dryRun, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"got/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const editorTimeLayout = "2006-01-02 15:04:05"
+
+const editorHeader = `# Edit the entries below, one entry per line:
+#
+#   <id> <start> <end> <sheet> <note>
+#
+# where start and end are formatted as "2006-01-02 15:04:05", or end is "-"
+# for a running entry.  Remove a line to delete the entry, use "new" as the ID
+# to add an entry.  Lines starting with '#' are ignored.
+`
+
+func formatEditorEntry(e *types.Entry) string {
+	id := "new"
+	if e.ID != 0 {
+		id = strconv.FormatUint(e.ID, 10)
+	}
+
+	end := "-"
+	if e.End != nil {
+		end = e.End.Local().Format(editorTimeLayout)
+	}
+
+	return fmt.Sprintf(
+		"%s\t%s\t%s\t%s\t%s",
+		id,
+		e.Start.Local().Format(editorTimeLayout),
+		end,
+		e.Sheet,
+		e.Note,
+	)
+}
+
+func writeEditorEntries(w io.Writer, entries []*types.Entry) error {
+	if _, err := io.WriteString(w, editorHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := fmt.Fprintln(w, formatEditorEntry(entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextField returns the first whitespace separated field of str and the rest
+// of str after it.
+func nextField(str string) (field, rest string) {
+	str = strings.TrimLeft(str, " \t")
+	i := strings.IndexAny(str, " \t")
+	if i < 0 {
+		return str, ""
+	}
+	return str[:i], str[i:]
+}
+
+func parseEditorTime(str string) (time.Time, string, error) {
+	date, rest := nextField(str)
+	clock, rest := nextField(rest)
+
+	t, err := time.ParseInLocation(editorTimeLayout, date+" "+clock, time.Local)
+	return t, rest, err
+}
+
+func parseEditorEntry(line string) (*types.Entry, error) {
+	var e types.Entry
+	var err error
+
+	id, rest := nextField(line)
+	if id != "new" {
+		if e.ID, err = strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid ID %s", id)
+		}
+	}
+
+	if e.Start, rest, err = parseEditorTime(rest); err != nil {
+		return nil, errors.New("invalid start time")
+	}
+
+	if field, after := nextField(rest); field == "-" {
+		rest = after
+	} else {
+		var end time.Time
+		if end, rest, err = parseEditorTime(rest); err != nil {
+			return nil, errors.New("invalid end time")
+		}
+		e.End = &end
+	}
+
+	e.Sheet, rest = nextField(rest)
+	if e.Sheet == "" {
+		return nil, errors.New("no sheet")
+	}
+	e.Note = strings.TrimSpace(rest)
+
+	return &e, nil
+}
+
+func parseEditorEntries(text string) ([]*types.Entry, error) {
+	var res []*types.Entry
+	for i, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line == "" || line[0] == '#' {
+			continue
+		}
+
+		entry, err := parseEditorEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		res = append(res, entry)
+	}
+	return res, nil
+}
+
+// entryChanges are the changes needed to get from one list of entries to
+// another, Updates holds the entries with their new values.
+type entryChanges struct {
+	Inserts []*types.Entry
+	Updates []*types.Entry
+	Deletes []*types.Entry
+}
+
+func (c *entryChanges) Empty() bool {
+	return len(c.Inserts) == 0 && len(c.Updates) == 0 && len(c.Deletes) == 0
+}
+
+// diffEntries returns the changes from before to after, entries in after
+// that have an ID have to be in before.
+func diffEntries(before, after []*types.Entry) (*entryChanges, error) {
+	var res entryChanges
+
+	byID := make(map[uint64]*types.Entry)
+	for _, entry := range before {
+		byID[entry.ID] = entry
+	}
+
+	seen := make(map[uint64]bool)
+	for _, entry := range after {
+		if entry.ID == 0 {
+			res.Inserts = append(res.Inserts, entry)
+			continue
+		}
+
+		original, has := byID[entry.ID]
+		if !has {
+			return nil, fmt.Errorf("entry #%d is not one of the edited entries", entry.ID)
+		} else if seen[entry.ID] {
+			return nil, fmt.Errorf("entry #%d is given more than once", entry.ID)
+		}
+		seen[entry.ID] = true
+
+		if formatEditorEntry(entry) != formatEditorEntry(original) {
+			res.Updates = append(res.Updates, entry)
+		}
+	}
+
+	for _, entry := range before {
+		if !seen[entry.ID] {
+			res.Deletes = append(res.Deletes, entry)
+		}
+	}
+
+	return &res, nil
+}
+
+// validateEntries checks that the entries end after they start and that at
+// most one entry is running, including the running entry that is not being
+// edited.
+func validateEntries(entries []*types.Entry, running *types.Entry) error {
+	for _, entry := range entries {
+		if entry.End == nil {
+			if running != nil && running.ID != entry.ID {
+				return fmt.Errorf("entry #%d is already running", running.ID)
+			}
+			running = entry
+		} else if !entry.End.After(entry.Start) {
+			return fmt.Errorf("entry starting at %s does not end after it starts", entry.Start.Format(editorTimeLayout))
+		}
+	}
+	return nil
+}
+
+func writeEntryChanges(w io.Writer, c *entryChanges, before []*types.Entry) {
+	byID := make(map[uint64]*types.Entry)
+	for _, entry := range before {
+		byID[entry.ID] = entry
+	}
+
+	for _, entry := range c.Deletes {
+		fmt.Fprintf(w, "delete: %s\n", formatEditorEntry(entry))
+	}
+	for _, entry := range c.Updates {
+		fmt.Fprintf(w, "update: %s\n", formatEditorEntry(byID[entry.ID]))
+		fmt.Fprintf(w, "    to: %s\n", formatEditorEntry(entry))
+	}
+	for _, entry := range c.Inserts {
+		fmt.Fprintf(w, "insert: %s\n", formatEditorEntry(entry))
+	}
+}
+
+// runEditor opens the file in the given editor, falling back to $VISUAL,
+// $EDITOR and vi.
+func runEditor(fname, editor string) error {
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], fname)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editEntriesInEditor writes the entries to a temporary file, opens it in the
+// editor of the user and returns the entries parsed from the result.
+func editEntriesInEditor(entries []*types.Entry, editor string) ([]*types.Entry, error) {
+	f, err := ioutil.TempFile("", "got-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if err := writeEditorEntries(f, entries); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := runEditor(f.Name(), editor); err != nil {
+		return nil, err
+	}
+
+	text, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	return parseEditorEntries(string(text))
+}
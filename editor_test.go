@@ -0,0 +1,109 @@
+package main
+
+import (
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestParseEditorEntry(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	end := time.Date(2026, 1, 5, 12, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		line     string
+		expected *types.Entry
+	}{
+		{"3\t2026-01-05 09:00:00\t2026-01-05 12:30:00\tmain\twriting", &types.Entry{ID: 3, Start: start, End: &end, Sheet: "main", Note: "writing"}},
+		{"3 2026-01-05 09:00:00 - work  a longer\tnote ", &types.Entry{ID: 3, Start: start, Sheet: "work", Note: "a longer\tnote"}},
+		{"new 2026-01-05 09:00:00 2026-01-05 12:30:00 main", &types.Entry{Start: start, End: &end, Sheet: "main"}},
+	}
+
+	for _, test := range tests {
+		entry, err := parseEditorEntry(test.line)
+		if err != nil {
+			t.Errorf("parseEditorEntry(%q): %s", test.line, err)
+		} else if formatEditorEntry(entry) != formatEditorEntry(test.expected) {
+			t.Errorf("parseEditorEntry(%q) = %q, expected %q", test.line, formatEditorEntry(entry), formatEditorEntry(test.expected))
+		}
+
+		// the entries are written as they are parsed
+		if again, err := parseEditorEntry(formatEditorEntry(test.expected)); err != nil || formatEditorEntry(again) != formatEditorEntry(test.expected) {
+			t.Errorf("%q is not read back as it is written", formatEditorEntry(test.expected))
+		}
+	}
+
+	for _, line := range []string{
+		"x 2026-01-05 09:00:00 - main",
+		"3 2026-01-05 - main",
+		"3 yesterday 09:00:00 - main",
+		"3 2026-01-05 09:00:00 12:30:00 main",
+		"3 2026-01-05 09:00:00 -",
+		"3 2026-01-05 09:00:00 2026-01-05 12:30:00",
+	} {
+		if entry, err := parseEditorEntry(line); err == nil {
+			t.Errorf("parseEditorEntry(%q) = %q, expected an error", line, formatEditorEntry(entry))
+		}
+	}
+}
+
+func TestDiffEntries(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+	entry := func(id uint64, note string) *types.Entry {
+		return &types.Entry{ID: id, Start: start, End: &end, Sheet: "main", Note: note}
+	}
+
+	before := []*types.Entry{entry(1, "kept"), entry(2, "changed"), entry(3, "deleted")}
+	after := []*types.Entry{entry(0, "added"), entry(2, "changed later"), entry(1, "kept")}
+
+	changes, err := diffEntries(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Inserts) != 1 || changes.Inserts[0].Note != "added" {
+		t.Errorf("the inserts are %v, expected the added entry", changes.Inserts)
+	}
+	if len(changes.Updates) != 1 || changes.Updates[0].Note != "changed later" {
+		t.Errorf("the updates are %v, expected the changed entry", changes.Updates)
+	}
+	if len(changes.Deletes) != 1 || changes.Deletes[0].ID != 3 {
+		t.Errorf("the deletes are %v, expected entry 3", changes.Deletes)
+	}
+
+	if changes, err := diffEntries(before, before); err != nil || !changes.Empty() {
+		t.Errorf("diffing the same entries gave %+v, %v, expected no changes", changes, err)
+	}
+	if _, err := diffEntries(before, []*types.Entry{entry(4, "other")}); err == nil {
+		t.Errorf("an entry that is not edited gave no error")
+	}
+	if _, err := diffEntries(before, []*types.Entry{entry(1, "a"), entry(1, "b")}); err == nil {
+		t.Errorf("an entry given twice gave no error")
+	}
+}
+
+func TestValidateEntries(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+	running := &types.Entry{ID: 1, Start: start, Sheet: "main"}
+
+	tests := []struct {
+		entries []*types.Entry
+		running *types.Entry
+		valid   bool
+	}{
+		{[]*types.Entry{{ID: 2, Start: start, End: &end}, {ID: 3, Start: end}}, nil, true},
+		// the running entry itself is edited
+		{[]*types.Entry{{ID: 1, Start: end}}, running, true},
+		{[]*types.Entry{{ID: 2, Start: end}}, running, false},
+		{[]*types.Entry{{ID: 2, Start: start}, {Start: end}}, nil, false},
+		{[]*types.Entry{{ID: 2, Start: end, End: &start}}, nil, false},
+		{[]*types.Entry{{ID: 2, Start: start, End: &start}}, nil, false},
+	}
+
+	for i, test := range tests {
+		if err := validateEntries(test.entries, test.running); (err == nil) != test.valid {
+			t.Errorf("test %d: validateEntries gave %v, expected valid to be %v", i, err, test.valid)
+		}
+	}
+}
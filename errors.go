@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// The exit codes, so scripts can tell the kinds of errors apart.
+const (
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitState    = 4
+	exitDatabase = 5
+	exitConfig   = 6
+	exitHook     = 7
+)
+
+// errorKinds are the names of the exit codes in JSON errors.
+var errorKinds = map[int]string{
+	exitError:    "error",
+	exitUsage:    "usage",
+	exitNotFound: "not_found",
+	exitState:    "state",
+	exitDatabase: "database",
+	exitConfig:   "config",
+	exitHook:     "hook",
+}
+
+// codeError is an error with the exit code for it.
+type codeError struct {
+	code int
+	err  error
+}
+
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+func withCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codeError{code: code, err: err}
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return withCode(exitUsage, fmt.Errorf(format, args...))
+}
+
+func notFoundErrorf(format string, args ...interface{}) error {
+	return withCode(exitNotFound, fmt.Errorf(format, args...))
+}
+
+func stateErrorf(format string, args ...interface{}) error {
+	return withCode(exitState, fmt.Errorf(format, args...))
+}
+
+// exitCode returns the exit code for the error, errors from the database
+// that have no code are database errors.
+func exitCode(err error) int {
+	var e *codeError
+	if errors.As(err, &e) {
+		return e.code
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return exitDatabase
+	}
+	return exitError
+}
+
+type jsonError struct {
+	Error string `json:"error"`
+	Kind  string `json:"kind"`
+	Code  int    `json:"code"`
+}
+
+// writeError writes the error on one line, as JSON with --output json, and
+// returns the exit code for it.
+func writeError(w io.Writer, err error, output string) int {
+	code := exitCode(err)
+	if output == "json" {
+		json.NewEncoder(w).Encode(jsonError{
+			Error: err.Error(),
+			Kind:  errorKinds[code],
+			Code:  code,
+		})
+	} else {
+		fmt.Fprintln(w, err)
+	}
+	return code
+}
+
+// fail writes the error to stderr and exits with the exit code for it.
+func fail(err error, output string) {
+	os.Exit(writeError(os.Stderr, err, output))
+}
+
+// failUsage is like fail for usage errors, in text output it writes the help
+// after the error when help is not nil.
+func failUsage(err error, help func(), output string) {
+	if output != "json" && help != nil {
+		fmt.Fprintf(os.Stderr, "%s\n\n", err)
+		help()
+		os.Exit(exitUsage)
+	}
+	fail(withCode(exitUsage, err), output)
+}
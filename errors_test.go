@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{errors.New("failed"), exitError},
+		{usageErrorf("no grouping given"), exitUsage},
+		{notFoundErrorf("no entry with id 3"), exitNotFound},
+		{stateErrorf("not running"), exitState},
+		{withCode(exitConfig, errors.New("invalid")), exitConfig},
+		{fmt.Errorf("pre-in hook: %w", withCode(exitHook, errors.New("exit status 1"))), exitHook},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, exitDatabase},
+		{fmt.Errorf("storing: %w", sqlite3.Error{Code: sqlite3.ErrLocked}), exitDatabase},
+	}
+
+	for _, test := range tests {
+		if code := exitCode(test.err); code != test.code {
+			t.Errorf("exitCode(%s) = %d, expected %d", test.err, code, test.code)
+		}
+	}
+
+	if withCode(exitState, nil) != nil {
+		t.Errorf("withCode of no error gave an error")
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	var buf bytes.Buffer
+	if code := writeError(&buf, notFoundErrorf("no entry with id 3"), "json"); code != exitNotFound {
+		t.Errorf("the exit code is %d, expected %d", code, exitNotFound)
+	}
+
+	var res map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("the error %q isn't JSON: %s", buf.String(), err)
+	}
+	expected := map[string]interface{}{
+		"error": "no entry with id 3",
+		"kind":  "not_found",
+		"code":  float64(exitNotFound),
+	}
+	if len(res) != len(expected) {
+		t.Errorf("the error is %v, expected %v", res, expected)
+	}
+	for key, value := range expected {
+		if res[key] != value {
+			t.Errorf("%s of the error is %v, expected %v", key, res[key], value)
+		}
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("the error %q isn't on one line", buf.String())
+	}
+
+	buf.Reset()
+	if code := writeError(&buf, errors.New("failed"), "text"); code != exitError {
+		t.Errorf("the exit code is %d, expected %d", code, exitError)
+	}
+	if buf.String() != "failed\n" {
+		t.Errorf("the error is %q, expected %q", buf.String(), "failed\n")
+	}
+}
@@ -4,45 +4,239 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+	"unicode"
 )
 
-// FlagSet is a stupid flag system that parses long flags only and gives you
-// unparsed input (free form input) and the flags with their values.
+// Type is the type of the value of a flag.
+type Type int
+
+const (
+	String Type = iota
+	// Bool flags do not take a value.
+	Bool
+	Duration
+	// Time values are not checked by the flag set, since they can be in
+	// natural language.
+	Time
+	// List values are comma separated, the values of repeated List flags are
+	// joined.
+	List
+)
+
+func (t Type) placeholder() string {
+	switch t {
+	case Bool:
+		return ""
+	case Duration:
+		return " <duration>"
+	case Time:
+		return " <time>"
+	case List:
+		return " <list>"
+	}
+	return " <value>"
+}
+
+// Spec declares a flag.
+type Spec struct {
+	Name string
+	// Short is the single letter alias of the flag, or zero.
+	Short       rune
+	Type        Type
+	Default     string
+	Description string
+}
+
+// Usage returns how the flag is given, like "--start, -s <time>".
+func (s Spec) Usage() string {
+	res := "--" + s.Name
+	if s.Short != 0 {
+		res += ", -" + string(s.Short)
+	}
+	return res + s.Type.placeholder()
+}
+
+// Help returns the usage of the flag with its description and default.
+func (s Spec) Help() string {
+	res := s.Usage() + ": " + s.Description
+	if s.Default != "" {
+		res += fmt.Sprintf(" (default %s)", s.Default)
+	}
+	return res
+}
+
+// FlagSet is a simple flag system that parses long flags, like "--start 9:00"
+// and "--start=9:00", and their single letter aliases, like "-s 9:00".  It
+// gives you the unparsed input (free form input) and the flags with their
+// values.  The words after a "--" are never parsed as flags.
 type FlagSet struct {
 	Values  map[string]string
 	Strings []string
+
+	bools   map[string]bool
+	aliases map[string]string
+	lists   map[string][]string
+	types   map[string]Type
+	given   []string
 }
 
 func MakeFlagSet(Values map[string]string) *FlagSet {
 	return &FlagSet{
 		Values:  Values,
 		Strings: []string{},
+		bools:   make(map[string]bool),
+		aliases: make(map[string]string),
+		lists:   make(map[string][]string),
+		types:   make(map[string]Type),
+	}
+}
+
+// NewFlagSet returns a flag set with the given flags.
+func NewFlagSet(specs []Spec) *FlagSet {
+	s := MakeFlagSet(make(map[string]string))
+	for _, spec := range specs {
+		s.Values[spec.Name] = spec.Default
+		s.types[spec.Name] = spec.Type
+		if spec.Type == Bool {
+			s.Bool(spec.Name)
+		}
+		if spec.Short != 0 {
+			s.Alias(spec.Short, spec.Name)
+		}
+	}
+	return s
+}
+
+// Bool adds a flag that does not take a value, its value is "true" when it's
+// given and "false" otherwise.  "--name=false" can be used to unset it.
+func (s *FlagSet) Bool(name string) {
+	s.Values[name] = "false"
+	s.bools[name] = true
+}
+
+// IsBool returns whether the flag with the given name does not take a value.
+func (s *FlagSet) IsBool(name string) bool {
+	return s.bools[name]
+}
+
+// Alias makes "-short" an alias for "--name".
+func (s *FlagSet) Alias(short rune, name string) {
+	s.aliases[string(short)] = name
+}
+
+// Aliases returns the single letter aliases of the flags, by flag name.
+func (s *FlagSet) Aliases() map[string]string {
+	res := make(map[string]string)
+	for short, name := range s.aliases {
+		res[name] = short
 	}
+	return res
+}
+
+// All returns all values given for the flag, in order.  Values holds the last
+// one, or all of them joined for List flags.
+func (s *FlagSet) All(name string) []string {
+	return s.lists[name]
+}
+
+// Given returns the names of the flags that were given, in order.
+func (s *FlagSet) Given() []string {
+	return s.given
+}
+
+// Lookup splits a flag argument like "--name=value" or "-n" in the name of the
+// flag, with aliases resolved, and the value after the '=' if there is one.
+// ok is false when the argument is not a flag, unknown flags are still
+// returned.  Arguments like "-15m" are not flags, since aliases are letters.
+func (s *FlagSet) Lookup(arg string) (name, value string, hasValue, ok bool) {
+	switch {
+	case strings.HasPrefix(arg, "--") && len(arg) > 2:
+		name = arg[2:]
+	case len(arg) > 1 && arg[0] == '-' && unicode.IsLetter(rune(arg[1])):
+		name = arg[1:]
+	default:
+		return "", "", false, false
+	}
+
+	if i := strings.Index(name, "="); i >= 0 {
+		name, value, hasValue = name[:i], name[i+1:], true
+	}
+	if !strings.HasPrefix(arg, "--") {
+		if long, has := s.aliases[name]; has {
+			name = long
+		} else {
+			name = "-" + name
+		}
+	}
+	return name, value, hasValue, true
+}
+
+func (s *FlagSet) set(name, value string) error {
+	if s.types[name] == Duration {
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid duration \"%s\" for flag %s", value, name)
+		}
+	}
+
+	if len(s.lists[name]) == 0 {
+		s.given = append(s.given, name)
+	}
+	s.lists[name] = append(s.lists[name], value)
+
+	if s.types[name] == List {
+		value = strings.Join(s.lists[name], ",")
+	}
+	s.Values[name] = value
+	return nil
 }
 
 func (s *FlagSet) Parse() error {
-	for i := 1; i < len(os.Args); i++ {
-		arg := os.Args[i]
+	return s.ParseArgs(os.Args[1:])
+}
+
+// ParseArgs is like Parse, but parses the given arguments instead of the
+// arguments of the program.
+func (s *FlagSet) ParseArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
 		if arg == "--" {
+			s.Strings = append(s.Strings, args[i+1:]...)
 			return nil
 		}
 
-		if strings.HasPrefix(arg, "--") {
-			name := arg[2:]
-			if _, has := s.Values[name]; !has {
-				return fmt.Errorf("unknown flag %s", name)
+		name, value, hasValue, ok := s.Lookup(arg)
+		if !ok {
+			s.Strings = append(s.Strings, arg)
+			continue
+		}
+
+		if _, has := s.Values[name]; !has {
+			return fmt.Errorf("unknown flag %s", name)
+		}
+
+		if s.bools[name] {
+			if !hasValue {
+				value = "true"
+			} else if value != "true" && value != "false" {
+				return fmt.Errorf("flag %s does not take a value", name)
 			}
+			if err := s.set(name, value); err != nil {
+				return err
+			}
+			continue
+		}
 
-			if i+1 >= len(os.Args) {
+		if !hasValue {
+			if i+1 >= len(args) {
 				return fmt.Errorf("no value for flag %s", name)
 			}
-
-			s.Values[name] = os.Args[i+1]
+			value = args[i+1]
 			i++
-			continue
 		}
-
-		s.Strings = append(s.Strings, arg)
+		if err := s.set(name, value); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,140 @@
+package flag
+
+import (
+	"strings"
+	"testing"
+)
+
+var testSpecs = []Spec{
+	{Name: "start", Short: 's', Type: Time},
+	{Name: "note", Type: String},
+	{Name: "yes", Short: 'y', Type: Bool},
+	{Name: "id", Short: 'i', Type: List},
+	{Name: "min", Type: Duration, Default: "1m"},
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		values  map[string]string
+		strings []string
+	}{
+		{
+			args:    []string{"in", "--start", "9:00", "work"},
+			values:  map[string]string{"start": "9:00", "yes": "false", "min": "1m"},
+			strings: []string{"in", "work"},
+		},
+		{
+			args:    []string{"-s", "9:00", "-y", "in"},
+			values:  map[string]string{"start": "9:00", "yes": "true"},
+			strings: []string{"in"},
+		},
+		{
+			args:    []string{"--note=a=b", "--yes=false", "--min=5m"},
+			values:  map[string]string{"note": "a=b", "yes": "false", "min": "5m"},
+			strings: []string{},
+		},
+		{
+			args:   []string{"-i", "1,2", "--id", "5-7", "--id=9"},
+			values: map[string]string{"id": "1,2,5-7,9"},
+		},
+		{
+			args:   []string{"--note", "a", "--note", "b"},
+			values: map[string]string{"note": "b"},
+		},
+		{
+			// relative times are not flags
+			args:    []string{"in", "-s", "-15m", "-15m"},
+			values:  map[string]string{"start": "-15m"},
+			strings: []string{"in", "-15m"},
+		},
+		{
+			args:    []string{"in", "--", "--start", "-y"},
+			values:  map[string]string{"start": "", "yes": "false"},
+			strings: []string{"in", "--start", "-y"},
+		},
+	}
+
+	for _, test := range tests {
+		s := NewFlagSet(testSpecs)
+		if err := s.ParseArgs(test.args); err != nil {
+			t.Errorf("%q: %s", test.args, err)
+			continue
+		}
+
+		for name, value := range test.values {
+			if s.Values[name] != value {
+				t.Errorf("%q: --%s is %q, expected %q", test.args, name, s.Values[name], value)
+			}
+		}
+		if test.strings != nil && strings.Join(s.Strings, " ") != strings.Join(test.strings, " ") {
+			t.Errorf("%q: the words are %q, expected %q", test.args, s.Strings, test.strings)
+		}
+	}
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--bogus"}, "unknown flag bogus"},
+		{[]string{"-x"}, "unknown flag -x"},
+		{[]string{"--note"}, "no value for flag note"},
+		{[]string{"--yes=maybe"}, "flag yes does not take a value"},
+		{[]string{"--min", "soon"}, "invalid duration \"soon\" for flag min"},
+	}
+
+	for _, test := range tests {
+		err := NewFlagSet(testSpecs).ParseArgs(test.args)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: error is %v, expected %s", test.args, err, test.err)
+		}
+	}
+}
+
+func TestGivenAndAll(t *testing.T) {
+	s := NewFlagSet(testSpecs)
+	if err := s.ParseArgs([]string{"--note", "a", "-y", "--note", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if given := strings.Join(s.Given(), ","); given != "note,yes" {
+		t.Errorf("the given flags are %s, expected note,yes", given)
+	}
+	if all := strings.Join(s.All("note"), ","); all != "a,b" {
+		t.Errorf("the values of --note are %s, expected a,b", all)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s := NewFlagSet(testSpecs)
+
+	tests := []struct {
+		arg      string
+		name     string
+		value    string
+		hasValue bool
+		ok       bool
+	}{
+		{"--start", "start", "", false, true},
+		{"--start=9:00", "start", "9:00", true, true},
+		{"-s", "start", "", false, true},
+		{"-s=9:00", "start", "9:00", true, true},
+		{"-q", "-q", "", false, true},
+		{"-15m", "", "", false, false},
+		{"in", "", "", false, false},
+		{"--", "", "", false, false},
+	}
+
+	for _, test := range tests {
+		name, value, hasValue, ok := s.Lookup(test.arg)
+		if name != test.name || value != test.value || hasValue != test.hasValue || ok != test.ok {
+			t.Errorf(
+				"Lookup(%s) = %s, %s, %v, %v, expected %s, %s, %v, %v",
+				test.arg, name, value, hasValue, ok,
+				test.name, test.value, test.hasValue, test.ok,
+			)
+		}
+	}
+}
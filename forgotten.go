@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"got/config"
+	"got/types"
+	"got/utils"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// forgottenWarning returns a warning if the running entry looks like it was
+// not stopped when it should have been, or an empty string otherwise.
+func forgottenWarning(entry *types.Entry, max time.Duration, now time.Time) string {
+	duration := now.Sub(entry.Start)
+
+	var reason string
+	if max > 0 && duration > max {
+		reason = fmt.Sprintf("has been running for %s", utils.FormatDuration(duration))
+	} else if !utils.SameDate(entry.Start, now) {
+		reason = fmt.Sprintf("has been running since %s", entry.Start.Format("Mon Jan 2, 2006 15:04:05"))
+	} else {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"warning: entry #%d on sheet \"%s\" %s, did you forget to check out?  see `out --at-last-activity`",
+		entry.ID,
+		entry.Sheet,
+		reason,
+	)
+}
+
+// runLastActivityHook runs the given shell command and parses its output as
+// the time of the last activity.
+func runLastActivityHook(hook string, now time.Time) (time.Time, error) {
+	out, err := exec.Command("sh", "-c", hook).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("last activity hook: %s", err)
+	}
+
+	str := strings.TrimSpace(string(out))
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+	t, err := parseTime(str, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("last activity hook: invalid time \"%s\"", str)
+	}
+	return t, nil
+}
+
+// lastActivity returns a sensible end time for the running entry: the time
+// given by the last activity hook or else the end of the working day the
+// entry started on.
+func lastActivity(cfg *config.Config, schedule *config.Schedule, entry *types.Entry, now time.Time) (time.Time, error) {
+	if cfg.LastActivityHook != "" {
+		t, err := runLastActivityHook(cfg.LastActivityHook, now)
+		if err != nil {
+			return t, err
+		} else if t.Before(entry.Start) || t.After(now) {
+			return t, fmt.Errorf("last activity hook: %s is not between the start of the entry and now", t.Format("Mon Jan 2, 2006 15:04:05"))
+		}
+		return t, nil
+	}
+
+	_, end, ok := schedule.WorkingHours(entry.Start)
+	if !ok || !end.After(entry.Start) {
+		return time.Time{}, errors.New("the entry did not start on a working day, use --end or --at instead")
+	}
+	if end.After(now) {
+		end = now
+	}
+	return end, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForgottenWarning(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	entry := &types.Entry{ID: 4, Start: start, Sheet: "main"}
+
+	tests := []struct {
+		max     time.Duration
+		now     time.Time
+		warning string
+	}{
+		{10 * time.Hour, start.Add(2 * time.Hour), ""},
+		{10 * time.Hour, start.Add(11 * time.Hour), "has been running for 11:00:00"},
+		{time.Hour, start.Add(90 * time.Minute), "has been running for 1:30:00"},
+		// zero disables the maximum, but not the warning for another day
+		{0, start.Add(14 * time.Hour), ""},
+		{0, start.Add(24 * time.Hour), "has been running since Mon Jan 5, 2026 09:00:00"},
+		{10 * time.Hour, start.Add(16 * time.Hour), "has been running for 16:00:00"},
+	}
+
+	for _, test := range tests {
+		warning := forgottenWarning(entry, test.max, test.now)
+		if test.warning == "" && warning != "" {
+			t.Errorf("with a maximum of %s at %s the warning is %q, expected none", test.max, test.now, warning)
+		} else if test.warning != "" && !strings.Contains(warning, "entry #4 on sheet \"main\" "+test.warning) {
+			t.Errorf("with a maximum of %s at %s the warning is %q, expected it to say %q", test.max, test.now, warning, test.warning)
+		}
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	// Monday
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local)
+	now := time.Date(2026, 1, 6, 10, 0, 0, 0, time.Local)
+	entry := &types.Entry{ID: 4, Start: start, Sheet: "main"}
+	schedule := config.DefaultSchedule()
+
+	tests := []struct {
+		hook     string
+		expected time.Time
+	}{
+		// the end of the working day
+		{"", time.Date(2026, 1, 5, 17, 0, 0, 0, time.Local)},
+		{"echo 2026-01-05T15:30:00Z", time.Date(2026, 1, 5, 15, 30, 0, 0, time.UTC)},
+		{"echo '  2026-01-05T18:00  '", time.Date(2026, 1, 5, 18, 0, 0, 0, time.Local)},
+	}
+
+	for _, test := range tests {
+		cfg := config.Default()
+		cfg.LastActivityHook = test.hook
+		end, err := lastActivity(cfg, schedule, entry, now)
+		if err != nil {
+			t.Errorf("the last activity with the hook %q: %s", test.hook, err)
+		} else if !end.Equal(test.expected) {
+			t.Errorf("the last activity with the hook %q is %s, expected %s", test.hook, end, test.expected)
+		}
+	}
+
+	for _, hook := range []string{
+		"exit 1",
+		"echo garbage",
+		"echo",
+		// before the start and after now
+		"echo 2026-01-04T12:00:00Z",
+		"echo 2026-01-07T12:00:00Z",
+	} {
+		cfg := config.Default()
+		cfg.LastActivityHook = hook
+		if end, err := lastActivity(cfg, schedule, entry, now); err == nil {
+			t.Errorf("the last activity with the hook %q is %s, expected an error", hook, end)
+		}
+	}
+
+	// on the weekend there is no working day to end
+	saturday := &types.Entry{ID: 5, Start: time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local), Sheet: "main"}
+	if end, err := lastActivity(config.Default(), schedule, saturday, now.AddDate(0, 0, 5)); err == nil {
+		t.Errorf("the last activity of an entry started on Saturday is %s, expected an error", end)
+	}
+}
@@ -0,0 +1,161 @@
+package formatters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"got/types"
+	"got/utils"
+	"io"
+	"strings"
+	"time"
+)
+
+// CSV writes comma separated values, or values separated by Delimiter if it's
+// set.
+type CSV struct {
+	Delimiter rune
+}
+
+func init() {
+	Register(&Registration{
+		Name:        "csv",
+		Aliases:     []string{"CSV"},
+		Description: "comma separated values for spreadsheets",
+		Options: []Option{
+			{Name: "delimiter", Description: "the character to separate the values with, 'tab' for a tab", Default: ","},
+		},
+		New: func(opts map[string]string) (types.Formatter, error) {
+			delimiter := opts["delimiter"]
+			if delimiter == "tab" {
+				delimiter = "\t"
+			}
+
+			runes := []rune(delimiter)
+			if len(runes) != 1 || strings.ContainsRune("\"\r\n", runes[0]) {
+				return nil, fmt.Errorf("invalid delimiter %s", opts["delimiter"])
+			}
+			return &CSV{Delimiter: runes[0]}, nil
+		},
+	})
+}
+
+func (c CSV) writer(out io.Writer) *csv.Writer {
+	w := csv.NewWriter(out)
+	if c.Delimiter != 0 {
+		w.Comma = c.Delimiter
+	}
+	return w
+}
+
+func (c CSV) Write(out io.Writer, f *types.FormatterInput) error {
+	w := c.writer(out)
+
+	if err := w.Write([]string{"id", "sheet", "start", "end", "duration", "note"}); err != nil {
+		return err
+	}
+
+	for _, entry := range f.Entries {
+		end := ""
+		if entry.End != nil {
+			end = entry.End.Format(time.RFC3339)
+		}
+
+		duration, _ := entry.Duration()
+
+		if err := w.Write([]string{
+			fmt.Sprint(entry.ID),
+			entry.Sheet,
+			entry.Start.Format(time.RFC3339),
+			end,
+			utils.FormatDuration(f.Rounding.Entry(duration)),
+			entry.Note,
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteReport writes a row for every group, with a column per grouping.  The
+// columns of the deeper groupings are left empty in the rows of the parent
+// groups, so those rows contain the subtotals.
+func (c CSV) WriteReport(out io.Writer, r *types.Report) error {
+	w := c.writer(out)
+
+	header := append([]string{}, r.GroupBy...)
+	header = append(header, "duration", "percentage")
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	var writeGroups func(groups []*types.ReportGroup, keys []string) error
+	writeGroups = func(groups []*types.ReportGroup, keys []string) error {
+		for _, group := range groups {
+			row := make([]string, len(r.GroupBy))
+			copy(row, keys)
+			row[len(keys)] = group.Key
+			row = append(
+				row,
+				utils.FormatDuration(group.Duration),
+				fmt.Sprintf("%.1f", group.Percentage),
+			)
+			if err := w.Write(row); err != nil {
+				return err
+			}
+
+			if err := writeGroups(group.Groups, append(keys, group.Key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := writeGroups(r.Groups, nil); err != nil {
+		return err
+	}
+
+	row := make([]string, len(r.GroupBy))
+	row[0] = "total"
+	row = append(row, utils.FormatDuration(r.Total), "100.0")
+	if err := w.Write(row); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// WriteStats writes a row per statistic, the most frequent notes are written
+// as "note" rows with the note, the count and the duration.
+func (c CSV) WriteStats(out io.Writer, s *types.Stats) error {
+	w := c.writer(out)
+
+	day := func(d *types.DayStats) []string {
+		if d == nil {
+			return []string{"", ""}
+		}
+		return []string{utils.FormatDuration(d.Duration), d.Date.Format("2006-01-02")}
+	}
+
+	rows := [][]string{
+		{"name", "value"},
+		{"entries", fmt.Sprint(s.Entries)},
+		{"days", fmt.Sprint(s.Days)},
+		{"total_time", utils.FormatDuration(s.Total)},
+		{"workdays", fmt.Sprint(s.Workdays)},
+		{"average_workday", utils.FormatDuration(s.AverageWorkday)},
+		{"average_entry", utils.FormatDuration(s.AverageEntry)},
+		append([]string{"longest_day"}, day(s.LongestDay)...),
+		append([]string{"shortest_day"}, day(s.ShortestDay)...),
+		{"longest_streak", fmt.Sprint(s.LongestStreak)},
+		{"current_streak", fmt.Sprint(s.CurrentStreak)},
+		{"average_switches", fmt.Sprintf("%.1f", s.AverageSwitches)},
+	}
+	for _, note := range s.Notes {
+		rows = append(rows, []string{"note", note.Note, fmt.Sprint(note.Count), utils.FormatDuration(note.Duration)})
+	}
+
+	w.WriteAll(rows)
+	return w.Error()
+}
@@ -5,12 +5,24 @@ import (
 	"got/types"
 	"got/utils"
 	"io"
+	"strings"
 	"text/tabwriter"
 	"time"
 )
 
 type Human struct{}
 
+func init() {
+	Register(&Registration{
+		Name:        "human",
+		Aliases:     []string{"text"},
+		Description: "tables for reading",
+		New: func(map[string]string) (types.Formatter, error) {
+			return &Human{}, nil
+		},
+	})
+}
+
 func (Human) Write(out io.Writer, f *types.FormatterInput) error {
 	fmt.Printf("Timesheet: %s\n", f.Sheet)
 	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
@@ -28,8 +40,8 @@ func (Human) Write(out io.Writer, f *types.FormatterInput) error {
 		}
 
 		duration, _ := entry.Duration()
+		duration = f.Rounding.Entry(duration)
 		dayDuration += duration
-		totalDuration += duration
 
 		dateString := ""
 		if newDay {
@@ -54,6 +66,8 @@ func (Human) Write(out io.Writer, f *types.FormatterInput) error {
 
 		if next == nil || !utils.SameDate(next.Start, entry.Start) {
 			// new day
+			dayDuration = f.Rounding.Day(dayDuration)
+			totalDuration += dayDuration
 
 			if i > 0 {
 				fmt.Fprintf(
@@ -75,3 +89,64 @@ func (Human) Write(out io.Writer, f *types.FormatterInput) error {
 
 	return w.Flush()
 }
+
+func (Human) WriteReport(out io.Writer, r *types.Report) error {
+	fmt.Fprintf(out, "Report by %s\n", strings.Join(r.GroupBy, ", "))
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Group\tDuration\tShare")
+
+	var writeGroups func(groups []*types.ReportGroup, depth int)
+	writeGroups = func(groups []*types.ReportGroup, depth int) {
+		for _, group := range groups {
+			fmt.Fprintf(
+				w,
+				"%s%s\t%s\t%5.1f%%\n",
+				strings.Repeat("  ", depth),
+				group.Key,
+				utils.FormatDuration(group.Duration),
+				group.Percentage,
+			)
+			writeGroups(group.Groups, depth+1)
+		}
+	}
+	writeGroups(r.Groups, 0)
+
+	fmt.Fprintf(w, "\t\t\n")
+	fmt.Fprintf(w, "Total\t%s\t\n", utils.FormatDuration(r.Total))
+
+	return w.Flush()
+}
+
+func (Human) WriteStats(out io.Writer, s *types.Stats) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	day := func(d *types.DayStats) string {
+		if d == nil {
+			return ""
+		}
+		return fmt.Sprintf("%s (%s)", utils.FormatDuration(d.Duration), d.Date.Format("Mon Jan 2, 2006"))
+	}
+
+	fmt.Fprintf(w, "Entries\t%d\n", s.Entries)
+	fmt.Fprintf(w, "Days tracked\t%d\n", s.Days)
+	fmt.Fprintf(w, "Total time\t%s\n", utils.FormatDuration(s.Total))
+	fmt.Fprintf(w, "Workdays\t%d\n", s.Workdays)
+	fmt.Fprintf(w, "Average per workday\t%s\n", utils.FormatDuration(s.AverageWorkday))
+	fmt.Fprintf(w, "Longest day\t%s\n", day(s.LongestDay))
+	fmt.Fprintf(w, "Shortest day\t%s\n", day(s.ShortestDay))
+	fmt.Fprintf(w, "Average entry\t%s\n", utils.FormatDuration(s.AverageEntry))
+	fmt.Fprintf(w, "Switches per day\t%.1f\n", s.AverageSwitches)
+	fmt.Fprintf(w, "Longest streak\t%d days\n", s.LongestStreak)
+	fmt.Fprintf(w, "Current streak\t%d days\n", s.CurrentStreak)
+
+	if len(s.Notes) > 0 {
+		fmt.Fprintf(w, "\t\n")
+		fmt.Fprintf(w, "Most frequent notes\tCount\tDuration\n")
+		for _, note := range s.Notes {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", note.Note, note.Count, utils.FormatDuration(note.Duration))
+		}
+	}
+
+	return w.Flush()
+}
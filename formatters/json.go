@@ -2,9 +2,12 @@ package formatters
 
 import (
 	"encoding/json"
+	"fmt"
 	"got/types"
 	"got/utils"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,9 +30,36 @@ type output struct {
 	TotalTime string        `json:"total_time"`
 }
 
-type JSON struct{}
+// JSON writes a JSON document, indented with Indent if it's not empty.
+type JSON struct {
+	Indent string
+}
 
-func (JSON) Write(out io.Writer, f *types.FormatterInput) error {
+func init() {
+	Register(&Registration{
+		Name:        "json",
+		Aliases:     []string{"JSON"},
+		Description: "a JSON document for scripts",
+		Options: []Option{
+			{Name: "indent", Description: "the amount of spaces to indent with, 0 writes everything on one line", Default: "0"},
+		},
+		New: func(opts map[string]string) (types.Formatter, error) {
+			indent, err := strconv.ParseUint(opts["indent"], 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid indent %s", opts["indent"])
+			}
+			return &JSON{Indent: strings.Repeat(" ", int(indent))}, nil
+		},
+	})
+}
+
+func (j JSON) encode(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", j.Indent)
+	return enc.Encode(v)
+}
+
+func (j JSON) Write(out io.Writer, f *types.FormatterInput) error {
 	var res output
 
 	sheets := make(map[string]*outputSheet)
@@ -38,7 +68,7 @@ func (JSON) Write(out io.Writer, f *types.FormatterInput) error {
 		sheetName := entry.Sheet
 
 		if _, has := sheets[sheetName]; !has {
-			sheetTime := utils.SumDuration(f.Entries, func(x *types.Entry) bool {
+			sheetTime := f.Rounding.Sum(f.Entries, func(x *types.Entry) bool {
 				return x.Sheet == sheetName
 			})
 
@@ -56,7 +86,7 @@ func (JSON) Write(out io.Writer, f *types.FormatterInput) error {
 			Start:    entry.Start,
 			End:      entry.End,
 			Note:     entry.Note,
-			Duration: utils.FormatDuration(entryDuration),
+			Duration: utils.FormatDuration(f.Rounding.Entry(entryDuration)),
 		})
 	}
 
@@ -64,9 +94,114 @@ func (JSON) Write(out io.Writer, f *types.FormatterInput) error {
 		res.Sheets = append(res.Sheets, *sheet)
 	}
 
-	totalTime := utils.SumDuration(f.Entries, func(*types.Entry) bool { return true })
+	totalTime := f.Rounding.Sum(f.Entries, func(*types.Entry) bool { return true })
 	res.TotalTime = utils.FormatDuration(totalTime)
 
-	enc := json.NewEncoder(out)
-	return enc.Encode(res)
+	return j.encode(out, res)
+}
+
+type outputReportGroup struct {
+	Key        string              `json:"key"`
+	Duration   string              `json:"duration"`
+	Percentage float64             `json:"percentage"`
+	Groups     []outputReportGroup `json:"groups,omitempty"`
+}
+
+type outputReport struct {
+	GroupBy   []string            `json:"group_by"`
+	Start     *time.Time          `json:"start"`
+	End       *time.Time          `json:"end"`
+	TotalTime string              `json:"total_time"`
+	Groups    []outputReportGroup `json:"groups"`
+}
+
+func makeOutputReportGroups(groups []*types.ReportGroup) []outputReportGroup {
+	var res []outputReportGroup
+	for _, group := range groups {
+		res = append(res, outputReportGroup{
+			Key:        group.Key,
+			Duration:   utils.FormatDuration(group.Duration),
+			Percentage: group.Percentage,
+			Groups:     makeOutputReportGroups(group.Groups),
+		})
+	}
+	return res
+}
+
+func (j JSON) WriteReport(out io.Writer, r *types.Report) error {
+	res := outputReport{
+		GroupBy:   r.GroupBy,
+		Start:     r.Start,
+		End:       r.End,
+		TotalTime: utils.FormatDuration(r.Total),
+		Groups:    makeOutputReportGroups(r.Groups),
+	}
+
+	return j.encode(out, res)
+}
+
+type outputDayStats struct {
+	Date     string `json:"date"`
+	Duration string `json:"duration"`
+}
+
+type outputNoteStats struct {
+	Note     string `json:"note"`
+	Count    int    `json:"count"`
+	Duration string `json:"duration"`
+}
+
+type outputStats struct {
+	Start           *time.Time        `json:"start"`
+	End             *time.Time        `json:"end"`
+	Entries         int               `json:"entries"`
+	Days            int               `json:"days"`
+	TotalTime       string            `json:"total_time"`
+	Workdays        int               `json:"workdays"`
+	AverageWorkday  string            `json:"average_workday"`
+	AverageEntry    string            `json:"average_entry"`
+	LongestDay      *outputDayStats   `json:"longest_day"`
+	ShortestDay     *outputDayStats   `json:"shortest_day"`
+	LongestStreak   int               `json:"longest_streak"`
+	CurrentStreak   int               `json:"current_streak"`
+	AverageSwitches float64           `json:"average_switches"`
+	Notes           []outputNoteStats `json:"notes"`
+}
+
+func makeOutputDayStats(d *types.DayStats) *outputDayStats {
+	if d == nil {
+		return nil
+	}
+	return &outputDayStats{
+		Date:     d.Date.Format("2006-01-02"),
+		Duration: utils.FormatDuration(d.Duration),
+	}
+}
+
+func (j JSON) WriteStats(out io.Writer, s *types.Stats) error {
+	res := outputStats{
+		Start:           s.Start,
+		End:             s.End,
+		Entries:         s.Entries,
+		Days:            s.Days,
+		TotalTime:       utils.FormatDuration(s.Total),
+		Workdays:        s.Workdays,
+		AverageWorkday:  utils.FormatDuration(s.AverageWorkday),
+		AverageEntry:    utils.FormatDuration(s.AverageEntry),
+		LongestDay:      makeOutputDayStats(s.LongestDay),
+		ShortestDay:     makeOutputDayStats(s.ShortestDay),
+		LongestStreak:   s.LongestStreak,
+		CurrentStreak:   s.CurrentStreak,
+		AverageSwitches: s.AverageSwitches,
+		Notes:           []outputNoteStats{},
+	}
+	for _, note := range s.Notes {
+		res.Notes = append(res.Notes, outputNoteStats{
+			Note:     note.Note,
+			Count:    note.Count,
+			Duration: utils.FormatDuration(note.Duration),
+		})
+	}
+
+	return j.encode(out, res)
 }
@@ -0,0 +1,110 @@
+package formatters
+
+import (
+	"fmt"
+	"got/types"
+	"strings"
+)
+
+// Option is an option of a formatter, given as --formatter-opt name=value.
+type Option struct {
+	Name        string
+	Description string
+	Default     string
+}
+
+// Registration describes a formatter.
+type Registration struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Options     []Option
+
+	// New returns the formatter with the given options, which are all in
+	// Options.
+	New func(opts map[string]string) (types.Formatter, error)
+}
+
+func (r *Registration) match(name string) bool {
+	if r.Name == name {
+		return true
+	}
+	for _, alias := range r.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registration) hasOption(name string) bool {
+	for _, option := range r.Options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registry holds the formatters in the order they are registered.
+var registry []*Registration
+
+// Register adds a formatter, formatters register themselves on init.
+func Register(r *Registration) {
+	for _, name := range append([]string{r.Name}, r.Aliases...) {
+		if Get(name) != nil {
+			panic("formatter already exists: " + name)
+		}
+	}
+	registry = append(registry, r)
+}
+
+// All returns all formatters.
+func All() []*Registration {
+	return registry
+}
+
+// Names returns the names of all formatters, without the aliases.
+func Names() []string {
+	var res []string
+	for _, r := range registry {
+		res = append(res, r.Name)
+	}
+	return res
+}
+
+// Get returns the formatter with the given name or alias, or nil if there is
+// none.
+func Get(name string) *Registration {
+	for _, r := range registry {
+		if r.match(name) {
+			return r
+		}
+	}
+	return nil
+}
+
+// New returns the formatter with the given name, configured with options
+// like "delimiter=;".
+func New(name string, options []string) (types.Formatter, error) {
+	r := Get(name)
+	if r == nil {
+		return nil, fmt.Errorf("unknown formatter %s, can be %s", name, strings.Join(Names(), ", "))
+	}
+
+	opts := make(map[string]string)
+	for _, option := range r.Options {
+		opts[option.Name] = option.Default
+	}
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid formatter option %s, expected name=value", option)
+		} else if !r.hasOption(parts[0]) {
+			return nil, fmt.Errorf("formatter %s has no option %s", r.Name, parts[0])
+		}
+		opts[parts[0]] = parts[1]
+	}
+
+	return r.New(opts)
+}
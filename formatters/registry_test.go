@@ -0,0 +1,65 @@
+package formatters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []string
+		expected interface{}
+	}{
+		{"csv", nil, &CSV{Delimiter: ','}},
+		{"CSV", []string{"delimiter=tab"}, &CSV{Delimiter: '\t'}},
+		{"json", []string{"indent=2"}, &JSON{Indent: "  "}},
+		// the last value of an option wins
+		{"json", []string{"indent=2", "indent=0"}, &JSON{}},
+		{"text", nil, &Human{}},
+	}
+
+	for _, test := range tests {
+		formatter, err := New(test.name, test.options)
+		if err != nil {
+			t.Errorf("New(%s, %q): %s", test.name, test.options, err)
+			continue
+		}
+
+		switch expected := test.expected.(type) {
+		case *CSV:
+			if f, ok := formatter.(*CSV); !ok || *f != *expected {
+				t.Errorf("New(%s, %q) = %#v, expected %#v", test.name, test.options, formatter, expected)
+			}
+		case *JSON:
+			if f, ok := formatter.(*JSON); !ok || *f != *expected {
+				t.Errorf("New(%s, %q) = %#v, expected %#v", test.name, test.options, formatter, expected)
+			}
+		case *Human:
+			if _, ok := formatter.(*Human); !ok {
+				t.Errorf("New(%s, %q) = %#v, expected %#v", test.name, test.options, formatter, expected)
+			}
+		}
+	}
+
+	invalid := []struct {
+		name    string
+		options []string
+		message string
+	}{
+		{"ical", nil, "unknown formatter ical"},
+		{"", nil, "unknown formatter"},
+		// options of other formatters
+		{"csv", []string{"indent=2"}, "formatter csv has no option indent"},
+		{"human", []string{"delimiter=;"}, "formatter human has no option delimiter"},
+		{"json", []string{"indent"}, "invalid formatter option indent"},
+		{"json", []string{"indent=-1"}, "invalid indent -1"},
+		{"csv", []string{"delimiter=ab"}, "invalid delimiter ab"},
+	}
+
+	for _, test := range invalid {
+		if _, err := New(test.name, test.options); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("New(%s, %q) gave the error %v, expected %q", test.name, test.options, err, test.message)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"time"
+)
+
+// gap is an interval in which nothing was tracked.
+type gap struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (g gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// findGaps returns the untracked intervals of at least min between the entries
+// on the given day, within the working hours of that day.  On days without
+// working hours only the intervals between the first and the last entry are
+// used.  Nothing after now is a gap.
+func findGaps(schedule *config.Schedule, entries []*types.Entry, day, now time.Time, min time.Duration) []gap {
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	end := func(e *types.Entry) time.Time {
+		if e.End == nil {
+			return now
+		}
+		return *e.End
+	}
+
+	var onDay []*types.Entry
+	for _, entry := range entries {
+		if entry.Start.Before(dayEnd) && end(entry).After(dayStart) {
+			onDay = append(onDay, entry)
+		}
+	}
+
+	from, to, ok := schedule.WorkingHours(day)
+	if !ok {
+		if len(onDay) == 0 {
+			return nil
+		}
+
+		from, to = onDay[0].Start, end(onDay[0])
+		for _, entry := range onDay {
+			if end(entry).After(to) {
+				to = end(entry)
+			}
+		}
+	}
+	if to.After(now) {
+		to = now
+	}
+
+	var res []gap
+	add := func(start, end time.Time) {
+		if g := (gap{start, end}); g.Duration() > 0 && g.Duration() >= min {
+			res = append(res, g)
+		}
+	}
+
+	cursor := from
+	for _, entry := range onDay {
+		if !cursor.Before(to) {
+			break
+		}
+
+		if entry.Start.After(cursor) {
+			if entry.Start.Before(to) {
+				add(cursor, entry.Start)
+			} else {
+				add(cursor, to)
+			}
+		}
+		if e := end(entry); e.After(cursor) {
+			cursor = e
+		}
+	}
+	if cursor.Before(to) {
+		add(cursor, to)
+	}
+
+	return res
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestFindGaps(t *testing.T) {
+	// a Monday, working from 9:00 to 17:00
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+	at := func(day time.Time, hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	entry := func(day time.Time, from, to int) *types.Entry {
+		end := at(day, to, 0)
+		return &types.Entry{Start: at(day, from, 0), End: &end}
+	}
+	running := &types.Entry{Start: at(monday, 14, 0)}
+
+	tests := []struct {
+		name     string
+		entries  []*types.Entry
+		day      time.Time
+		now      time.Time
+		min      time.Duration
+		expected []gap
+	}{
+		{
+			name:     "no entries",
+			day:      monday,
+			now:      at(monday, 20, 0),
+			expected: []gap{{at(monday, 9, 0), at(monday, 17, 0)}},
+		},
+		{
+			name:    "between and around entries",
+			entries: []*types.Entry{entry(monday, 8, 10), entry(monday, 11, 12), entry(monday, 13, 16)},
+			day:     monday,
+			now:     at(monday, 20, 0),
+			expected: []gap{
+				{at(monday, 10, 0), at(monday, 11, 0)},
+				{at(monday, 12, 0), at(monday, 13, 0)},
+				{at(monday, 16, 0), at(monday, 17, 0)},
+			},
+		},
+		{
+			name:     "shorter than min",
+			entries:  []*types.Entry{entry(monday, 9, 10), entry(monday, 11, 17)},
+			day:      monday,
+			now:      at(monday, 20, 0),
+			min:      2 * time.Hour,
+			expected: nil,
+		},
+		{
+			name:     "nothing after now",
+			entries:  []*types.Entry{entry(monday, 9, 10)},
+			day:      monday,
+			now:      at(monday, 12, 30),
+			expected: []gap{{at(monday, 10, 0), at(monday, 12, 30)}},
+		},
+		{
+			name:     "running entry",
+			entries:  []*types.Entry{entry(monday, 9, 12), running},
+			day:      monday,
+			now:      at(monday, 15, 0),
+			expected: []gap{{at(monday, 12, 0), at(monday, 14, 0)}},
+		},
+		{
+			name:     "between the entries on a day off",
+			entries:  []*types.Entry{entry(saturday, 10, 11), entry(saturday, 13, 14)},
+			day:      saturday,
+			now:      at(saturday, 20, 0),
+			expected: []gap{{at(saturday, 11, 0), at(saturday, 13, 0)}},
+		},
+	}
+
+	for _, test := range tests {
+		gaps := findGaps(config.DefaultSchedule(), test.entries, test.day, test.now, test.min)
+		if len(gaps) != len(test.expected) {
+			t.Errorf("%s: gaps are %v, expected %v", test.name, gaps, test.expected)
+			continue
+		}
+		for i := range gaps {
+			if !gaps[i].Start.Equal(test.expected[i].Start) || !gaps[i].End.Equal(test.expected[i].End) {
+				t.Errorf("%s: gaps are %v, expected %v", test.name, gaps, test.expected)
+				break
+			}
+		}
+	}
+}
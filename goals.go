@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"got/types"
+	"got/utils"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// goalProgress is the time spent on a sheet on a day and in the week of that
+// day, together with the goal of the sheet.
+type goalProgress struct {
+	Goal  *types.Goal
+	Today time.Duration
+	Week  time.Duration
+}
+
+func remaining(goal, done time.Duration) time.Duration {
+	if done >= goal {
+		return 0
+	}
+	return goal - done
+}
+
+func sumDay(entries []*types.Entry, day time.Time) time.Duration {
+	return utils.SumDuration(entries, func(e *types.Entry) bool {
+		return utils.SameDate(day, e.Start)
+	})
+}
+
+func sumWeek(entries []*types.Entry, day time.Time, weekStart time.Weekday) time.Duration {
+	start := utils.StartOfWeek(day, weekStart)
+	end := start.AddDate(0, 0, 7)
+	return utils.SumDuration(entries, func(e *types.Entry) bool {
+		return !e.Start.Before(start) && e.Start.Before(end)
+	})
+}
+
+// getGoalProgress returns the progress of the given sheet on the given day, or
+// nil if the sheet has no goal.
+func getGoalProgress(state *State, sheet string, day time.Time, weekStart time.Weekday) (*goalProgress, error) {
+	goal, err := state.GetGoal(sheet)
+	if err != nil || goal == nil {
+		return nil, err
+	}
+
+	entries, err := state.GetAllEntries(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goalProgress{
+		Goal:  goal,
+		Today: sumDay(entries, day),
+		Week:  sumWeek(entries, day, weekStart),
+	}, nil
+}
+
+// Summary returns the goal and the remaining time as short strings, the
+// daily goal is preferred over the weekly one.
+func (p *goalProgress) Summary() (goal, left string) {
+	if p.Goal.Daily > 0 {
+		return utils.FormatDuration(p.Goal.Daily) + "/day", utils.FormatDuration(remaining(p.Goal.Daily, p.Today))
+	}
+	return utils.FormatDuration(p.Goal.Weekly) + "/week", utils.FormatDuration(remaining(p.Goal.Weekly, p.Week))
+}
+
+func (p *goalProgress) Write(w io.Writer) {
+	if p.Goal.Daily > 0 {
+		fmt.Fprintf(
+			w,
+			"today: %s of %s (%s remaining)\n",
+			utils.FormatDuration(p.Today),
+			utils.FormatDuration(p.Goal.Daily),
+			utils.FormatDuration(remaining(p.Goal.Daily, p.Today)),
+		)
+	}
+	if p.Goal.Weekly > 0 {
+		fmt.Fprintf(
+			w,
+			"week: %s of %s (%s remaining)\n",
+			utils.FormatDuration(p.Week),
+			utils.FormatDuration(p.Goal.Weekly),
+			utils.FormatDuration(remaining(p.Goal.Weekly, p.Week)),
+		)
+	}
+}
+
+// writeGoalWeek writes how every day of the week of now compared to the daily
+// goal of the sheet, followed by the week total.
+func writeGoalWeek(out io.Writer, goal *types.Goal, entries []*types.Entry, now time.Time, weekStart time.Weekday) error {
+	fmt.Fprintf(out, "Timesheet: %s\n", goal.Sheet)
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "Day\tWorked\tGoal\tDifference")
+
+	formatGoal := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return utils.FormatDuration(d)
+	}
+
+	start := utils.StartOfWeek(now, weekStart)
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i)
+
+		worked, difference := "", ""
+		if !day.After(now) {
+			d := sumDay(entries, day)
+			worked = utils.FormatDuration(d)
+			if goal.Daily > 0 {
+				difference = utils.FormatSignedDuration(d - goal.Daily)
+			}
+		}
+
+		fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\n",
+			day.Format("Mon Jan 2, 2006"),
+			worked,
+			formatGoal(goal.Daily),
+			difference,
+		)
+	}
+
+	week := sumWeek(entries, now, weekStart)
+	difference := ""
+	if goal.Weekly > 0 {
+		difference = utils.FormatSignedDuration(week - goal.Weekly)
+	}
+	fmt.Fprintf(w, "\t\t\t\n")
+	fmt.Fprintf(w, "Week\t%s\t%s\t%s\n", utils.FormatDuration(week), formatGoal(goal.Weekly), difference)
+
+	return w.Flush()
+}
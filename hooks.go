@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"got/config"
+	"got/types"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"time"
+)
+
+// The events hooks can be run on, a hook is the executable with the name of
+// the event in the hooks directory in the config directory.  A non-zero exit
+// of a pre- hook aborts the operation.
+//
+// post-edit is run for every entry that is changed or added by something other
+// than in, and post-kill for every removed entry.
+const (
+	hookPreIn       = "pre-in"
+	hookPostIn      = "post-in"
+	hookPreOut      = "pre-out"
+	hookPostOut     = "post-out"
+	hookPostEdit    = "post-edit"
+	hookPostKill    = "post-kill"
+	hookSheetSwitch = "sheet-switch"
+)
+
+type hookEntry struct {
+	ID    uint64     `json:"id,omitempty"`
+	Sheet string     `json:"sheet"`
+	Note  string     `json:"note"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+type hookSheets struct {
+	Sheet         string `json:"sheet"`
+	PreviousSheet string `json:"previous_sheet"`
+}
+
+// hooksDisabled is set during dry runs, which have no effects but the shown
+// changes.
+var hooksDisabled = false
+
+// runHook runs the hook for the given event, if there is one, with the payload
+// as JSON on stdin and the environment variables added to its environment.
+func runHook(event string, payload interface{}, env map[string]string) error {
+	if hooksDisabled {
+		return nil
+	}
+
+	dir, err := config.Path("hooks")
+	if err != nil {
+		return err
+	}
+
+	fname := path.Join(dir, event)
+	if _, err := os.Stat(fname); os.IsNotExist(err) {
+		return nil
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(fname)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	cmd.Env = append(os.Environ(), "GOT_EVENT="+event)
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	if err := cmd.Run(); err != nil {
+		return withCode(exitHook, fmt.Errorf("%s hook: %s", event, err))
+	}
+	return nil
+}
+
+// runEntryHook runs the hook for the given event with the entry as payload.
+func runEntryHook(event string, e *types.Entry) error {
+	env := map[string]string{
+		"GOT_ENTRY_ID":    "",
+		"GOT_ENTRY_SHEET": e.Sheet,
+		"GOT_ENTRY_NOTE":  e.Note,
+		"GOT_ENTRY_START": e.Start.Format(time.RFC3339),
+		"GOT_ENTRY_END":   "",
+	}
+	if e.ID != 0 {
+		env["GOT_ENTRY_ID"] = strconv.FormatUint(e.ID, 10)
+	}
+	if e.End != nil {
+		env["GOT_ENTRY_END"] = e.End.Format(time.RFC3339)
+	}
+
+	return runHook(event, hookEntry{
+		ID:    e.ID,
+		Sheet: e.Sheet,
+		Note:  e.Note,
+		Start: e.Start,
+		End:   e.End,
+	}, env)
+}
+
+// runPostHooks runs the hook for the given post- event for every entry, since
+// the operation is done a failing hook is only reported.
+func runPostHooks(event string, entries ...*types.Entry) {
+	for _, entry := range entries {
+		if err := runEntryHook(event, entry); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+func runSheetSwitchHook(sheet, previous string) {
+	err := runHook(hookSheetSwitch, hookSheets{
+		Sheet:         sheet,
+		PreviousSheet: previous,
+	}, map[string]string{
+		"GOT_SHEET":          sheet,
+		"GOT_PREVIOUS_SHEET": previous,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
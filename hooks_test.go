@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"got/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withHooks makes a config directory with the given hook scripts and uses it
+// like $XDG_CONFIG_HOME does, it returns the directory and a function that
+// removes it.
+func withHooks(t *testing.T, hooks map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "got")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove := func() {
+		os.RemoveAll(dir)
+	}
+
+	hooksDir := path.Join(dir, "got", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	for event, script := range hooks {
+		if err := ioutil.WriteFile(path.Join(hooksDir, event), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			remove()
+			t.Fatal(err)
+		}
+	}
+
+	old, had := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	return dir, func() {
+		if had {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+		remove()
+	}
+}
+
+func TestPreHookAborts(t *testing.T) {
+	_, remove := withHooks(t, map[string]string{hookPreIn: "exit 3"})
+	defer remove()
+
+	entry := &types.Entry{Start: time.Now(), Sheet: "main"}
+	err := runEntryHook(hookPreIn, entry)
+	if err == nil {
+		t.Fatal("a failing pre-in hook gave no error")
+	}
+	if code := exitCode(err); code != exitHook {
+		t.Errorf("a failing hook exits with %d, expected %d", code, exitHook)
+	}
+	if !strings.Contains(err.Error(), "pre-in hook") {
+		t.Errorf("the error %q doesn't name the hook", err)
+	}
+
+	// events without a hook do nothing
+	if err := runEntryHook(hookPreOut, entry); err != nil {
+		t.Errorf("running a missing hook: %s", err)
+	}
+}
+
+func TestHookInput(t *testing.T) {
+	dir, remove := withHooks(t, map[string]string{
+		hookPostOut: `cat > "$XDG_CONFIG_HOME/payload"; env | grep '^GOT_' | sort > "$XDG_CONFIG_HOME/env"`,
+	})
+	defer remove()
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	if err := runEntryHook(hookPostOut, &types.Entry{ID: 12, Start: start, End: &end, Sheet: "work", Note: "code review"}); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := ioutil.ReadFile(path.Join(dir, "payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry hookEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		t.Fatalf("the payload %q isn't JSON: %s", payload, err)
+	}
+	if entry.ID != 12 || entry.Sheet != "work" || entry.Note != "code review" || !entry.Start.Equal(start) || entry.End == nil || !entry.End.Equal(end) {
+		t.Errorf("the payload is %s, expected entry 12", payload)
+	}
+
+	env, err := ioutil.ReadFile(path.Join(dir, "env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"GOT_ENTRY_END=2026-01-05T10:30:00Z",
+		"GOT_ENTRY_ID=12",
+		"GOT_ENTRY_NOTE=code review",
+		"GOT_ENTRY_SHEET=work",
+		"GOT_ENTRY_START=2026-01-05T09:00:00Z",
+		"GOT_EVENT=post-out",
+	} {
+		if !strings.Contains(string(env), expected+"\n") {
+			t.Errorf("the environment %q doesn't contain %s", env, expected)
+		}
+	}
+}
+
+func TestHooksDisabled(t *testing.T) {
+	dir, remove := withHooks(t, map[string]string{
+		hookPreIn:   "exit 1",
+		hookPostOut: `touch "$XDG_CONFIG_HOME/ran"`,
+	})
+	defer remove()
+
+	hooksDisabled = true
+	defer func() {
+		hooksDisabled = false
+	}()
+
+	entry := &types.Entry{Start: time.Now(), Sheet: "main"}
+	if err := runEntryHook(hookPreIn, entry); err != nil {
+		t.Errorf("a disabled hook gave the error %s", err)
+	}
+	runPostHooks(hookPostOut, entry)
+	if _, err := os.Stat(path.Join(dir, "ran")); !os.IsNotExist(err) {
+		t.Errorf("a disabled hook ran")
+	}
+}
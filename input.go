@@ -1,10 +1,15 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"got/config"
 	"got/flag"
 	"got/formatters"
 	"got/types"
-	"strconv"
+	"got/utils"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -13,67 +18,337 @@ import (
 
 type Input struct {
 	Raw map[string]string
+	// Flags are the names of the given flags.
+	Flags []string
 
 	ID        uint64
+	IDs       []uint64
 	Start     time.Time
 	End       time.Time
 	At        time.Time
+	Since     time.Time
+	Day       time.Time
+	Min       time.Duration
+	For       time.Duration
+	Until     time.Time
 	Filter    string
+	WhereNote string
+	Sheet     string
+	GroupBy   []string
+	Daily     time.Duration
+	Weekly    time.Duration
+	// Formatter is only set for commands that take --formatter.
 	Formatter types.Formatter
+	// Answer is the answer to confirmations.
+	Answer utils.Answer
+	// Location is the time zone to show and enter times in.
+	Location *time.Location
+	// Rounding is nil when durations are not rounded.
+	Rounding *types.Rounding
+	// Output is how errors are written, "text" or "json".
+	Output string
+
+	formatterName    string
+	formatterOptions []string
 
 	Command string
 	Note    string
 }
 
-func GetInput() (Input, error) {
-	var res Input
+// dateLayouts are tried before falling back to natural language parsing,
+// which does not understand dates like "2026-01-01".  Layouts without a zone
+// are in the zone of now.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
 
-	fs := flag.MakeFlagSet(map[string]string{
-		"id": "0",
+// naturalWords are the words the natural language parser understands, it
+// silently skips other words, so "yesterdy 5pm" would be today.
+var naturalWords = map[string]bool{}
 
-		"start": "",
-		"end":   "",
-		"at":    "",
+func init() {
+	for _, word := range strings.Fields(`
+		now today yesterday tomorrow ago from in a an at on the of and
+		last past previous next this am pm st nd rd th
+		one two three four five six seven eight nine ten
+		minute minutes hour hours day days week weeks month months year years
+		monday tuesday wednesday thursday friday saturday sunday
+		january february march april may june july august september october
+		november december
+	`) {
+		naturalWords[word] = true
+	}
+}
 
-		"filter": "",
+// naturalTokenRegexp matches the numbers and clock times natural language can
+// contain, like "3", "3rd", "9:30" and "5pm".
+var naturalTokenRegexp = regexp.MustCompile(`^\d+(st|nd|rd|th|am|pm)?$|^\d{1,2}(:\d{2}){1,2}(am|pm)?$`)
 
-		"formatter": "human",
-	})
-	if err := fs.Parse(); err != nil {
-		return res, err
+// relativeTimeRegexp matches times relative to now, like "-15m" and "+2h".
+var relativeTimeRegexp = regexp.MustCompile(`^[+-]\d`)
+
+// parseTime parses an ISO 8601 time, a time relative to now like "-15m" or
+// "+2h", or a time in natural language like "yesterday 5pm".
+func parseTime(str string, now time.Time) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return time.Time{}, errors.New("no time given")
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, str, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if relativeTimeRegexp.MatchString(str) {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %s", str)
+		}
+		return now.Add(d), nil
+	}
+
+	for _, word := range strings.Fields(strings.ToLower(str)) {
+		word = strings.Trim(word, ",")
+		if !naturalWords[word] && !naturalTokenRegexp.MatchString(word) {
+			return time.Time{}, fmt.Errorf("unknown word \"%s\"", word)
+		}
+	}
+	return nd.Parse(str, now)
+}
+
+// parseRounding returns the rounding from --round, --round-mode and
+// --round-per, falling back to the config.  --round 0 disables rounding.
+func parseRounding(values map[string]string, cfg *config.Config) (*types.Rounding, error) {
+	res := &types.Rounding{
+		To:     cfg.RoundInSeconds,
+		Mode:   cfg.RoundMode,
+		PerDay: cfg.RoundPerDay,
+	}
+
+	if round := values["round"]; round != "" {
+		var err error
+		if res.To, err = time.ParseDuration(round); err != nil {
+			return nil, err
+		} else if res.To < 0 {
+			return nil, fmt.Errorf("invalid rounding %s", round)
+		}
+	} else if !cfg.RoundByDefault {
+		return nil, nil
+	}
+	if res.To == 0 {
+		return nil, nil
+	}
+
+	if mode := values["round-mode"]; mode != "" {
+		if err := types.ValidateRoundingMode(mode); err != nil {
+			return nil, err
+		}
+		res.Mode = mode
+	}
+	switch values["round-per"] {
+	case "":
+	case "entry":
+		res.PerDay = false
+	case "day":
+		res.PerDay = true
+	default:
+		return nil, fmt.Errorf("invalid rounding unit %s, can be 'entry' or 'day'", values["round-per"])
+	}
+
+	return res, nil
+}
+
+// flagSpecs are all flags, commands accept a subset of them.
+var flagSpecs = []flag.Spec{
+	{Name: "id", Short: 'i', Type: flag.List, Description: "the ID to manipulate/copy.  defaults to the current or last entry.  can be a list of IDs and ranges like '3,5,10-14', or be repeated, for display, edit, move and kill"},
+	{Name: "where-note", Type: flag.String, Description: "select the entries with a note containing the given text"},
+	{Name: "at", Type: flag.Time, Description: "the time to use, this can be equal to --start or --end depending on the context.  always has a lower priority than --start or --end."},
+	{Name: "start", Short: 's', Type: flag.Time, Description: "the start time to use"},
+	{Name: "end", Short: 'e', Type: flag.Time, Description: "the end time to use"},
+	{Name: "day", Type: flag.Time, Description: "the day to use, or to select entries on"},
+	{Name: "note", Type: flag.String, Description: "the note to use, instead of the words after the command"},
+	{Name: "sheet", Type: flag.String, Description: "the sheet to use instead of the current one"},
+	{Name: "for", Type: flag.Duration, Description: "stop the started entry after the given duration, like '45m'"},
+	{Name: "until", Type: flag.Time, Description: "stop the started entry at the given time"},
+	{Name: "at-last-activity", Type: flag.Bool, Description: "stop the entry at the last activity or the end of the working day"},
+	{Name: "editor", Type: flag.Bool, Description: "edit the entries in $EDITOR"},
+	{Name: "dry-run", Type: flag.Bool, Description: "show the entries before and after the changes without making them.  confirmations are answered with yes and hooks are not run"},
+	{Name: "min", Type: flag.Duration, Default: "1m", Description: "the minimal length of a gap"},
+	{Name: "since", Type: flag.Time, Description: "the day to start computing the balance from"},
+	{Name: "yes", Short: 'y', Type: flag.Bool, Description: "answer yes to every confirmation, also done when $GOT_ASSUME_YES is set"},
+	{Name: "no", Type: flag.Bool, Description: "answer no to every confirmation.  confirmations are always answered with no when stdin is not a terminal"},
+	{Name: "output", Type: flag.String, Default: "text", Description: "how to write errors: 'text' or 'json'"},
+	{Name: "tz", Type: flag.String, Description: "the time zone to show and enter times in, like 'Europe/Amsterdam'.  defaults to timezone from the config, or the zone of the system"},
+	{Name: "round", Type: flag.Duration, Description: "round durations to the given duration, like '15m'.  '0' disables rounding.  defaults to round_in_seconds when round_by_default is set"},
+	{Name: "round-mode", Type: flag.String, Description: "how to round: 'nearest', 'up' or 'down'.  defaults to round_mode from the config"},
+	{Name: "round-per", Type: flag.String, Description: "round every 'entry' or the total of every 'day'.  defaults to round_per_day from the config"},
+	{Name: "formatter", Type: flag.String, Description: "the formatter to use, see 'formatters'.  defaults to default_formatter from the config.  commands that change entries only take it with --dry-run"},
+	{Name: "formatter-opt", Type: flag.String, Description: "an option of the formatter, like 'delimiter=;'.  can be repeated"},
+	{Name: "filter", Type: flag.String, Description: "filter some outputs based on entry note"},
+	{Name: "daily", Type: flag.Duration, Description: "the daily goal, like '8h'.  '0' removes the goal"},
+	{Name: "weekly", Type: flag.Duration, Description: "the weekly goal, like '40h'.  '0' removes the goal"},
+	{Name: "group-by", Type: flag.List, Default: "sheet", Description: "comma separated list of groupings, or repeated.  can be 'sheet', 'day', 'week', 'month', 'note' or 'tag'"},
+}
+
+// globalFlags are accepted by every command.
+var globalFlags = flagsNamed("tz", "yes", "no", "output")
+
+// flagsNamed returns the specs of the flags with the given names.
+func flagsNamed(names ...string) []flag.Spec {
+	var res []flag.Spec
+	for _, name := range names {
+		found := false
+		for _, spec := range flagSpecs {
+			if spec.Name == name {
+				res = append(res, spec)
+				found = true
+			}
+		}
+		if !found {
+			panic("unknown flag " + name)
+		}
+	}
+	return res
+}
+
+// parseAnswer returns the answer to confirmations from --yes, --no and
+// $GOT_ASSUME_YES, the flags win from the environment variable.
+func parseAnswer(values map[string]string) (utils.Answer, error) {
+	yes, no := values["yes"] == "true", values["no"] == "true"
+	switch {
+	case yes && no:
+		return utils.AnswerAsk, errors.New("--yes and --no cannot be given both")
+	case yes:
+		return utils.AnswerYes, nil
+	case no:
+		return utils.AnswerNo, nil
 	}
 
-	var err error
-	res.ID, err = strconv.ParseUint(fs.Values["id"], 10, 64)
+	switch strings.ToLower(os.Getenv("GOT_ASSUME_YES")) {
+	case "", "0", "false", "no":
+		return utils.AnswerAsk, nil
+	}
+	return utils.AnswerYes, nil
+}
+
+// makeFlagSet returns the flag set with all flags and their defaults.
+func makeFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet(flagSpecs)
+}
+
+// NewFormatter returns the formatter from --formatter and --formatter-opt,
+// or default_formatter from the config.
+func (in *Input) NewFormatter() (types.Formatter, error) {
+	return formatters.New(in.formatterName, in.formatterOptions)
+}
+
+// GetInput parses the given arguments, without the program name, using the
+// defaults from the config.
+func GetInput(args []string, cfg *config.Config) (Input, error) {
+	var res Input
+
+	fs := makeFlagSet()
+	err := fs.ParseArgs(args)
+	// the output is set first, so even errors in the other flags are written
+	// as asked
+	res.Output = fs.Values["output"]
 	if err != nil {
 		return res, err
 	}
-	startString := fs.Values["start"]
-	endString := fs.Values["end"]
-	atString := fs.Values["at"]
+	if res.Output != "text" && res.Output != "json" {
+		res.Output = "text"
+		return res, fmt.Errorf("invalid output %s, can be 'text' or 'json'", fs.Values["output"])
+	}
+	res.Flags = fs.Given()
+	if fs.Values["formatter"] == "" {
+		fs.Values["formatter"] = cfg.DefaultFormatter
+	}
 
-	res.Start, err = nd.Parse(startString, time.Now())
+	res.IDs, err = parseIDs(fs.Values["id"])
 	if err != nil {
-		res.Start = time.Time{}
+		return res, err
 	}
-	res.End, err = nd.Parse(endString, time.Now())
-	if err != nil {
-		res.End = time.Time{}
+	if len(res.IDs) > 0 {
+		res.ID = res.IDs[0]
 	}
-	res.At, err = nd.Parse(atString, time.Now())
-	if err != nil {
-		res.At = time.Time{}
+	if len(res.IDs) == 1 && res.ID == 0 {
+		res.IDs = nil
+	}
+	if res.Answer, err = parseAnswer(fs.Values); err != nil {
+		return res, err
 	}
-	res.Filter = fs.Values["filter"]
-	switch fs.Values["formatter"] {
-	case "human":
-		res.Formatter = &formatters.Human{}
-	case "json", "JSON":
-		res.Formatter = &formatters.JSON{}
 
-	default:
-		panic("invalid formatter " + fs.Values["formatter"])
+	tz := fs.Values["tz"]
+	if tz == "" {
+		tz = cfg.Timezone
+	}
+	res.Location = time.Local
+	if tz != "" {
+		if res.Location, err = time.LoadLocation(tz); err != nil {
+			return res, fmt.Errorf("unknown time zone %s", tz)
+		}
 	}
+	now := time.Now().In(res.Location)
+
+	times := []struct {
+		name string
+		dest *time.Time
+	}{
+		{"start", &res.Start},
+		{"end", &res.End},
+		{"at", &res.At},
+		{"since", &res.Since},
+		{"day", &res.Day},
+		{"until", &res.Until},
+	}
+	for _, t := range times {
+		value := fs.Values[t.name]
+		if value == "" {
+			continue
+		}
+		if *t.dest, err = parseTime(value, now); err != nil {
+			return res, fmt.Errorf("invalid time \"%s\" for --%s: %s", value, t.name, err)
+		}
+	}
+
+	if res.Min, err = time.ParseDuration(fs.Values["min"]); err != nil {
+		return res, err
+	}
+	if duration := fs.Values["for"]; duration != "" {
+		if res.For, err = time.ParseDuration(duration); err != nil {
+			return res, err
+		}
+	}
+	res.Filter = fs.Values["filter"]
+	res.WhereNote = fs.Values["where-note"]
+	res.Sheet = fs.Values["sheet"]
+	for _, name := range strings.Split(fs.Values["group-by"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			res.GroupBy = append(res.GroupBy, name)
+		}
+	}
+	if daily := fs.Values["daily"]; daily != "" {
+		if res.Daily, err = time.ParseDuration(daily); err != nil {
+			return res, err
+		}
+	}
+	if weekly := fs.Values["weekly"]; weekly != "" {
+		if res.Weekly, err = time.ParseDuration(weekly); err != nil {
+			return res, err
+		}
+	}
+	if res.Rounding, err = parseRounding(fs.Values, cfg); err != nil {
+		return res, err
+	}
+	res.formatterName = fs.Values["formatter"]
+	res.formatterOptions = fs.All("formatter-opt")
 
 	if len(fs.Strings) > 0 {
 		res.Command = fs.Strings[0]
@@ -86,6 +361,9 @@ func GetInput() (Input, error) {
 		res.Note += fs.Strings[i]
 	}
 	res.Note = strings.TrimSpace(res.Note)
+	if note := fs.Values["note"]; note != "" {
+		res.Note = note
+	}
 
 	res.Raw = fs.Values
 
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"got/config"
+	"got/utils"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("no time zone data: %s", err)
+	}
+	// the day the clocks go from 02:00 to 03:00
+	now := time.Date(2026, 3, 29, 12, 0, 0, 0, loc)
+
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{"2026-03-29T01:30", "2026-03-29T01:30:00+01:00"},
+		{"2026-03-29 15:04:05", "2026-03-29T15:04:05+02:00"},
+		{"2026-03-28", "2026-03-28T00:00:00+01:00"},
+		{"2026-03-29T10:00:00Z", "2026-03-29T10:00:00Z"},
+		{"-15m", "2026-03-29T11:45:00+02:00"},
+		{"+2h", "2026-03-29T14:00:00+02:00"},
+		// 11 hours before is before the change, an hour earlier on the clock
+		{"-11h", "2026-03-29T00:00:00+01:00"},
+		{"now", "2026-03-29T12:00:00+02:00"},
+	}
+
+	for _, test := range tests {
+		res, err := parseTime(test.str, now)
+		if err != nil {
+			t.Errorf("parseTime(%s): %s", test.str, err)
+		} else if str := res.Format(time.RFC3339); str != test.expected {
+			t.Errorf("parseTime(%s) = %s, expected %s", test.str, str, test.expected)
+		}
+	}
+
+	for _, str := range []string{"", "  ", "yesterdy 5pm", "-15x"} {
+		if res, err := parseTime(str, now); err == nil {
+			t.Errorf("parseTime(%s) = %s, expected an error", str, res)
+		}
+	}
+}
+
+func TestGetInputTimeZone(t *testing.T) {
+	input, err := GetInput([]string{"in", "--tz", "Europe/Amsterdam", "--start", "2026-10-25 12:00", "--until", "2026-10-26 12:00", "work"}, config.Default())
+	if err != nil {
+		t.Skipf("no time zone data: %s", err)
+	}
+
+	if name := input.Location.String(); name != "Europe/Amsterdam" {
+		t.Errorf("the location is %s, expected Europe/Amsterdam", name)
+	}
+	// the clocks go from 03:00 to 02:00 on October 25, 2026
+	if str := input.Start.Format(time.RFC3339); str != "2026-10-25T12:00:00+01:00" {
+		t.Errorf("the start is %s, expected 2026-10-25T12:00:00+01:00", str)
+	}
+	if d := input.Until.Sub(input.Start); d != 24*time.Hour {
+		t.Errorf("the until is %s after the start, expected 24h", d)
+	}
+	if input.Command != "in" || input.Note != "work" {
+		t.Errorf("the command is %s with note %s, expected in with note work", input.Command, input.Note)
+	}
+
+	if _, err := GetInput([]string{"in", "--tz", "Mars/Olympus"}, config.Default()); err == nil {
+		t.Errorf("an unknown time zone gave no error")
+	}
+}
+
+func TestGetInputErrors(t *testing.T) {
+	tests := [][]string{
+		{"in", "--start", "yesterdy"},
+		{"in", "--for", "soon"},
+		{"display", "--id", "a"},
+		{"display", "--round", "15m", "--round-mode", "sideways"},
+		{"--yes", "--no", "kill"},
+		{"--output", "xml", "now"},
+	}
+
+	for _, args := range tests {
+		if _, err := GetInput(args, config.Default()); err == nil {
+			t.Errorf("%q gave no error", args)
+		}
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	tests := []struct {
+		args []string
+		ok   bool
+	}{
+		{[]string{"display"}, true},
+		{[]string{"display", "--formatter", "csv", "--formatter-opt", "delimiter=;"}, true},
+		{[]string{"display", "--formatter", "bogus"}, false},
+		{[]string{"display", "--formatter", "csv", "--formatter-opt", "indent=2"}, false},
+	}
+
+	for _, test := range tests {
+		input, err := GetInput(test.args, config.Default())
+		if err != nil {
+			t.Errorf("%q: %s", test.args, err)
+			continue
+		}
+		if _, err := input.NewFormatter(); (err == nil) != test.ok {
+			t.Errorf("%q: the error is %v", test.args, err)
+		}
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	old, had := os.LookupEnv("GOT_ASSUME_YES")
+	defer func() {
+		if had {
+			os.Setenv("GOT_ASSUME_YES", old)
+		} else {
+			os.Unsetenv("GOT_ASSUME_YES")
+		}
+	}()
+
+	tests := []struct {
+		values   map[string]string
+		env      string
+		expected utils.Answer
+	}{
+		{map[string]string{}, "", utils.AnswerAsk},
+		{map[string]string{"yes": "true"}, "", utils.AnswerYes},
+		{map[string]string{"no": "true"}, "", utils.AnswerNo},
+		{map[string]string{}, "1", utils.AnswerYes},
+		{map[string]string{}, "No", utils.AnswerAsk},
+		{map[string]string{}, "false", utils.AnswerAsk},
+		// --no wins over the environment
+		{map[string]string{"no": "true"}, "1", utils.AnswerNo},
+	}
+
+	for _, test := range tests {
+		os.Setenv("GOT_ASSUME_YES", test.env)
+		answer, err := parseAnswer(test.values)
+		if err != nil {
+			t.Errorf("%v with GOT_ASSUME_YES=%s: %s", test.values, test.env, err)
+		} else if answer != test.expected {
+			t.Errorf("%v with GOT_ASSUME_YES=%s gave the answer %d, expected %d", test.values, test.env, answer, test.expected)
+		}
+	}
+
+	if _, err := parseAnswer(map[string]string{"yes": "true", "no": "true"}); err == nil {
+		t.Errorf("--yes and --no gave no error")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+	"3:04pm",
+	"3pm",
+}
+
+// parseClock parses a time of day like "9:00" or "5pm" on the given day.
+func parseClock(str string, day time.Time) (time.Time, error) {
+	for _, layout := range clockLayouts {
+		t, err := time.Parse(layout, strings.ToLower(str))
+		if err != nil {
+			continue
+		}
+
+		y, m, d := day.Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, day.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s", str)
+}
+
+// parseLogRange parses a range of times like "9:00-11:15" on the given day.
+// If the end is before the start, the range ends on the next day.
+func parseLogRange(str string, day time.Time) (start, end time.Time, err error) {
+	parts := strings.SplitN(str, "-", 2)
+	if len(parts) != 2 {
+		return start, end, fmt.Errorf("invalid range %s", str)
+	}
+
+	if start, err = parseClock(parts[0], day); err != nil {
+		return start, end, err
+	}
+	if end, err = parseClock(parts[1], day); err != nil {
+		return start, end, err
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
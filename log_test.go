@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogRange(t *testing.T) {
+	day := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	at := func(d, hour, min int) time.Time {
+		return time.Date(2026, 1, d, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		str        string
+		start, end time.Time
+	}{
+		{"9:00-11:15", at(5, 9, 0), at(5, 11, 15)},
+		{"09:00:00-17:30:00", at(5, 9, 0), at(5, 17, 30)},
+		{"9am-1:30PM", at(5, 9, 0), at(5, 13, 30)},
+		// reversed ranges end on the next day
+		{"22:00-1:00", at(5, 22, 0), at(6, 1, 0)},
+		{"9:00-9:00", at(5, 9, 0), at(6, 9, 0)},
+	}
+
+	for _, test := range tests {
+		start, end, err := parseLogRange(test.str, day)
+		if err != nil {
+			t.Errorf("parseLogRange(%s): %s", test.str, err)
+		} else if !start.Equal(test.start) || !end.Equal(test.end) {
+			t.Errorf("parseLogRange(%s) = %s - %s, expected %s - %s", test.str, start, end, test.start, test.end)
+		}
+	}
+
+	// open-ended and invalid ranges
+	for _, str := range []string{"9:00", "9:00-", "-11:15", "-", "9:00-noon", "25:00-26:00", "9:00-10:00-11:00", "1h30m"} {
+		if start, end, err := parseLogRange(str, day); err == nil {
+			t.Errorf("parseLogRange(%s) = %s - %s, expected an error", str, start, end)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"got/config"
+	"got/formatters"
 	"got/types"
 	"got/utils"
 	"io"
@@ -44,37 +46,74 @@ func writeEntry(e *types.Entry, w io.Writer) error {
 	return err
 }
 
+func writeEntries(entries []*types.Entry, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "Id\tDay\tStart      End\tDuration\tNotes")
+	for _, entry := range entries {
+		if err := writeEntry(entry, w); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s\n", os.Args[0])
 
-	printFlag := func(name, description string) {
-		fmt.Fprintf(os.Stderr, "\t--%s: %s\n", name, description)
+	fmt.Fprintf(os.Stderr, "\nflags are given as '--name value' or '--name=value', the words after '--' are never flags.  see 'help <command>' for the flags of a command.\n")
+	fmt.Fprintf(os.Stderr, "times are given as ISO 8601 like '2026-01-02T15:04', relative to now like '-15m' or '+2h', or in natural language like 'yesterday 5pm'.\n")
+	fmt.Fprintf(os.Stderr, "\nglobal flags:\n")
+	for _, spec := range globalFlags {
+		fmt.Fprintf(os.Stderr, "\t%s\n", spec.Help())
 	}
-	fmt.Fprintf(os.Stderr, "\nflags:\n")
-	printFlag("id", "the ID to manipulate/copy.  defaults to the current or last entry.")
-	printFlag("at", "the time to use, this can be equal to --start or --end depending on the context.  always has a lower priority than --start or --end.")
-	printFlag("start", "the start time to use")
-	printFlag("end", "the end time to use")
-	printFlag("formatter", "the formatter to use.  can be 'human' or 'json'")
-	printFlag("filter", "filter some outputs based on entry note")
 
 	fmt.Fprintf(os.Stderr, "\ncommands:\n")
 	cmds := commands.GetByPrefix("")
 	for _, cmd := range cmds {
+		if cmd.Hidden {
+			continue
+		}
 		fmt.Fprintf(os.Stderr, "\t%s: %s\n", strings.Join(cmd.Names, ", "), cmd.Description)
 	}
 
-	os.Exit(1)
+	if plugins := discoverPlugins(); len(plugins) > 0 {
+		fmt.Fprintf(os.Stderr, "\nplugins:\n")
+		for _, name := range plugins {
+			fmt.Fprintf(os.Stderr, "\t%s: runs %s%s\n", name, pluginPrefix, name)
+		}
+	}
+
+	if aliases, err := config.LoadAliases(); err == nil && len(aliases) > 0 {
+		fmt.Fprintf(os.Stderr, "\naliases:\n")
+		for _, alias := range aliases {
+			fmt.Fprintf(os.Stderr, "\t%s: %s\n", alias.Name, alias.Command)
+		}
+	}
 }
 
-func getState() (*State, error) {
+// getDatabasePath returns the database_file from the config, defaulting to
+// the database of timetrap.
+func getDatabasePath(cfg *config.Config) (string, error) {
+	if cfg.DatabaseFile != "" {
+		return config.ExpandHome(cfg.DatabaseFile)
+	}
+
 	homedir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	fname := path.Join(homedir, ".timetrap.db")
+	return path.Join(homedir, ".timetrap.db"), nil
+}
+
+// isConfigCommand tells whether the command is the config command, which still
+// runs when the config has errors so they can be fixed with it.  commands are registered later, so the prefix is matched here,
+// "co" is also the start of completion.
+func isConfigCommand(command string) bool {
+	return len(command) >= len("con") && strings.HasPrefix("config", command)
+}
 
+func getState(fname string) (*State, error) {
 	dbNew := false
 	if _, err := os.Stat(fname); os.IsNotExist(err) {
 		dbNew = true
@@ -90,29 +129,108 @@ func getState() (*State, error) {
 			return nil, err
 		}
 	}
+	if err := runMigrations(db); err != nil {
+		return nil, err
+	}
 
 	return MakeState(db)
 }
 
 func main() {
-	input, err := GetInput()
+	// errors in the config are only reported after the input is parsed, so
+	// they are written as --output asks
+	args := os.Args[1:]
+	aliases, cfgErr := config.LoadAliases()
+	if cfgErr == nil {
+		args, cfgErr = expandAlias(args, aliases)
+	}
+
+	cfg, err := config.Load()
+	if cfgErr == nil {
+		cfgErr = err
+	}
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	// the error is only returned when the command is not a plugin, since
+	// plugins have flags of their own
+	input, inputErr := GetInput(args, cfg)
+	utils.AssumedAnswer = input.Answer
+	if input.Location != nil {
+		// everything is shown in the local time zone
+		time.Local = input.Location
+	}
+	if cfgErr != nil && isConfigCommand(input.Command) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", cfgErr)
+	} else if cfgErr != nil {
+		fail(withCode(exitConfig, cfgErr), input.Output)
+	}
+
+	dbPath, err := getDatabasePath(cfg)
+	if err != nil {
+		fail(withCode(exitConfig, err), input.Output)
+	}
+
+	state, err := getState(dbPath)
 	if err != nil {
-		panic(err)
+		fail(withCode(exitDatabase, err), input.Output)
 	}
 
-	state, err := getState()
+	// completion should not print anything but the candidates
+	quiet := input.Command == completeCommand
+
+	// got is not running in the background, so entries with a planned end are
+	// stopped by the first invocation after it.
+	expired, err := state.StopExpiredEntry(time.Now())
 	if err != nil {
-		panic(err)
+		fail(withCode(exitDatabase, err), input.Output)
+	} else if expired != nil && !quiet {
+		fmt.Fprintf(
+			os.Stderr,
+			"Checked out of sheet \"%s\" (%d) at the planned end %s.\n",
+			expired.Sheet,
+			expired.ID,
+			expired.End.Format("15:04:05"),
+		)
+	}
+	if expired != nil {
+		runPostHooks(hookPostOut, expired)
+	}
+
+	// a dry run runs everything in a transaction that is rolled back after
+	// the changes are shown.  it starts after the expired entry is stopped,
+	// which is not a change made by the command.
+	dryRun := input.Raw["dry-run"] == "true"
+	var before *snapshot
+	if dryRun {
+		hooksDisabled = true
+		if utils.AssumedAnswer == utils.AnswerAsk {
+			utils.AssumedAnswer = utils.AnswerYes
+		}
+
+		if before, err = takeSnapshot(state); err != nil {
+			fail(withCode(exitDatabase, err), input.Output)
+		}
+		if err := state.BeginDryRun(); err != nil {
+			fail(withCode(exitDatabase, err), input.Output)
+		}
 	}
 
 	currentEntry, err := state.GetCurrentEntry()
 	if err != nil {
-		panic(err)
+		fail(withCode(exitDatabase, err), input.Output)
+	}
+
+	if currentEntry != nil && !quiet {
+		if warning := forgottenWarning(currentEntry, cfg.MaxRunning, time.Now()); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
 	}
 
 	meta, err := state.GetMeta()
 	if err != nil {
-		panic(err)
+		fail(withCode(exitDatabase, err), input.Output)
 	}
 
 	if input.ID == 0 {
@@ -126,7 +244,82 @@ func main() {
 		}
 	}
 
-	commands.AddCommand([]string{"in", "start"}, "start an entry", "[--start, --at (now)] [note (\"\")]", func() error {
+	sel := &selector{
+		IDs:  input.IDs,
+		Note: input.WhereNote,
+		Day:  input.Day,
+	}
+	// batch is true when the input can select more than one entry
+	batch := len(input.IDs) > 1 || input.WhereNote != "" || input.Day != (time.Time{})
+
+	selectEntries := func() ([]*types.Entry, error) {
+		entries, err := state.GetAllEntries("")
+		if err != nil {
+			return nil, err
+		}
+
+		selected := sel.Select(entries)
+		if len(selected) == 0 {
+			return nil, notFoundErrorf("no entries found")
+		}
+		return selected, nil
+	}
+	confirmBatch := func(prompt string, entries []*types.Entry) bool {
+		writeEntries(entries, os.Stderr)
+		return utils.Confirm(prompt, false)
+	}
+	// requireNote prompts for a note when the note is empty and a note is
+	// required.
+	requireNote := func(note, sheet string) (string, error) {
+		if note != "" || !cfg.RequireNote {
+			return note, nil
+		}
+		return promptNote(state, sheet)
+	}
+	// checkIn starts the entry with start, after stopping the running entry
+	// when auto_checkout is set.  The pre- hooks run before anything is
+	// changed and both changes are made in one transaction, so a failing hook
+	// or start changes nothing.
+	checkIn := func(entry *types.Entry, start func() error) error {
+		running, err := state.GetCurrentEntry()
+		if err != nil {
+			return err
+		} else if !cfg.AutoCheckout {
+			running = nil
+		}
+
+		if running != nil {
+			end := entry.Start
+			running.End = &end
+			if err := runEntryHook(hookPreOut, running); err != nil {
+				return err
+			}
+		}
+		if err := runEntryHook(hookPreIn, entry); err != nil {
+			return err
+		}
+
+		err = state.transaction(func(queryer) error {
+			if running != nil {
+				if err := state.StopEntry(running.ID, *running.End); err != nil {
+					return err
+				}
+			}
+			return start()
+		})
+		if err != nil {
+			return err
+		}
+
+		if running != nil {
+			runPostHooks(hookPostOut, running)
+			fmt.Printf("Checked out of sheet \"%s\" (%d).\n", running.Sheet, running.ID)
+		}
+		runPostHooks(hookPostIn, entry)
+		return nil
+	}
+
+	commands.AddCommand([]string{"in", "start"}, "start an entry", "[note]", flagsNamed("start", "at", "for", "until", "note", "dry-run", "formatter", "formatter-opt"), func() error {
 		start := input.Start
 		if start == (time.Time{}) {
 			start = input.At
@@ -135,17 +328,55 @@ func main() {
 			start = time.Now()
 		}
 
+		var plannedEnd time.Time
+		if input.For != 0 {
+			plannedEnd = start.Add(input.For)
+		} else if input.Until != (time.Time{}) {
+			plannedEnd = input.Until
+		}
+		if plannedEnd != (time.Time{}) && !plannedEnd.After(start) {
+			return usageErrorf("the planned end has to be after the start")
+		}
+
 		sheet := meta.CurrentSheet
 
-		id, err := state.StartEntry(input.Note, sheet, start)
+		note, err := requireNote(input.Note, sheet)
+		if err != nil {
+			return err
+		}
+
+		entry := &types.Entry{
+			Start: start,
+			Sheet: sheet,
+			Note:  note,
+		}
+		// an entry without its planned end would never be stopped, so both
+		// are stored in the transaction of checkIn
+		err = checkIn(entry, func() error {
+			id, err := state.StartEntry(entry.Note, sheet, start)
+			if err != nil {
+				return err
+			}
+			entry.ID = id
+
+			if plannedEnd != (time.Time{}) {
+				return state.SetPlannedEnd(id, plannedEnd)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Checked into sheet \"%s\" (%d).\n", sheet, id)
+		if plannedEnd != (time.Time{}) {
+			fmt.Printf("Checked into sheet \"%s\" (%d) until %s.\n", sheet, entry.ID, plannedEnd.Format("15:04:05"))
+			return nil
+		}
+
+		fmt.Printf("Checked into sheet \"%s\" (%d).\n", sheet, entry.ID)
 		return nil
 	})
-	commands.AddCommand([]string{"out", "end"}, "stop an entry", "[--end, --at (now)] [--id (current)]", func() error {
+	commands.AddCommand([]string{"out", "end"}, "stop an entry", "", flagsNamed("end", "at", "id", "at-last-activity", "dry-run", "formatter", "formatter-opt"), func() error {
 		end := input.End
 		if end == (time.Time{}) {
 			end = input.At
@@ -158,19 +389,109 @@ func main() {
 		if err != nil {
 			return err
 		} else if entry == nil {
-			return fmt.Errorf("no entry with ID %d found", input.ID)
+			return notFoundErrorf("no entry with ID %d found", input.ID)
+		}
+
+		if input.Raw["at-last-activity"] == "true" {
+			schedule, err := config.LoadSchedule()
+			if err != nil {
+				return err
+			}
+
+			end, err = lastActivity(cfg, schedule, entry, time.Now())
+			if err != nil {
+				return err
+			}
+
+			str := fmt.Sprintf("stop entry #%d (\"%s\") at %s?", entry.ID, entry.Note, end.Format("Mon Jan 2, 2006 15:04:05"))
+			if !utils.Confirm(str, true) {
+				return nil
+			}
+		}
+
+		entry.End = &end
+		if err := runEntryHook(hookPreOut, entry); err != nil {
+			return err
 		}
 
 		if err := state.StopEntry(input.ID, end); err != nil {
 			return err
 		}
+		runPostHooks(hookPostOut, entry)
 
 		sheet := meta.CurrentSheet
 
 		fmt.Printf("Checked out of sheet \"%s\" (%d).\n", sheet, input.ID)
 		return nil
 	})
-	commands.AddCommand([]string{"resume"}, "resume an entry", "[--start, --at (now)] [--id (last)]", func() error {
+	commands.AddCommand([]string{"log"}, "add a finished entry, without touching the running entry", "<duration> [note]\n<start-end> [note]", flagsNamed("start", "at", "end", "day", "sheet", "dry-run", "formatter", "formatter-opt"), func() error {
+		spec := input.Note
+		note := ""
+		if i := strings.Index(spec, " "); i >= 0 {
+			spec, note = spec[:i], strings.TrimSpace(spec[i+1:])
+		}
+		if spec == "" {
+			return usageErrorf("no duration or range given")
+		}
+
+		var start, end time.Time
+		if duration, err := time.ParseDuration(spec); err == nil {
+			if duration <= 0 {
+				return usageErrorf("duration has to be positive")
+			}
+
+			start = input.Start
+			if start == (time.Time{}) {
+				start = input.At
+			}
+
+			if start != (time.Time{}) {
+				end = start.Add(duration)
+			} else {
+				end = input.End
+				if end == (time.Time{}) {
+					end = time.Now()
+				}
+				start = end.Add(-duration)
+			}
+		} else {
+			day := input.Day
+			if day == (time.Time{}) {
+				day = time.Now()
+			}
+
+			if start, end, err = parseLogRange(spec, day); err != nil {
+				return err
+			}
+		}
+
+		sheet := input.Sheet
+		if sheet == "" {
+			sheet = meta.CurrentSheet
+		} else if strings.Contains(sheet, " ") {
+			return usageErrorf("name cannot contain spaces")
+		}
+
+		entry := &types.Entry{
+			Start: start,
+			End:   &end,
+			Sheet: sheet,
+			Note:  note,
+		}
+		if err := state.AddEntries([]*types.Entry{entry}); err != nil {
+			return err
+		}
+		runPostHooks(hookPostEdit, entry)
+
+		fmt.Printf(
+			"Logged %s into sheet \"%s\" (%d).\n",
+			utils.FormatDuration(end.Sub(start)),
+			sheet,
+			entry.ID,
+		)
+		return nil
+	})
+	commands.AddCommand([]string{"resume"}, "resume an entry", "", flagsNamed("start", "at", "id", "note", "dry-run", "formatter", "formatter-opt"), func() error {
 		start := input.Start
 		if start == (time.Time{}) {
 			start = input.At
@@ -180,56 +501,207 @@ func main() {
 		}
 
 		var entry *types.Entry
-		if id := input.Raw["id"]; id != "0" {
+		if id := input.Raw["id"]; id != "" && id != "0" {
 			var err error
 			entry, err = state.GetEntry(input.ID)
 			if err != nil {
 				return err
 			} else if entry == nil {
-				return fmt.Errorf("no entry with ID %s found", id)
+				return notFoundErrorf("no entry with ID %s found", id)
 			}
 
-			if entry.Sheet != meta.CurrentSheet {
-				state.SwitchSheet(entry.Sheet)
-			}
 		} else {
 			entry, err = state.GetLastEntry(meta.CurrentSheet)
 			if err != nil {
 				return err
 			} else if entry == nil {
-				return errors.New("no entries")
+				return notFoundErrorf("no entries")
 			}
 		}
 
-		newId, err := state.StartEntry(entry.Note, entry.Sheet, start)
+		note := entry.Note
+		if note == "" {
+			note = input.Note
+		}
+		note, err := requireNote(note, entry.Sheet)
+		if err != nil {
+			return err
+		}
+
+		resumed := &types.Entry{
+			Start: start,
+			Sheet: entry.Sheet,
+			Note:  note,
+		}
+		switchSheet := entry.Sheet != meta.CurrentSheet
+		err = checkIn(resumed, func() error {
+			if switchSheet {
+				if err := state.SwitchSheet(entry.Sheet); err != nil {
+					return err
+				}
+			}
+
+			id, err := state.StartEntry(resumed.Note, entry.Sheet, start)
+			resumed.ID = id
+			return err
+		})
 		if err != nil {
 			return err
 		}
+		if switchSheet {
+			runSheetSwitchHook(entry.Sheet, meta.CurrentSheet)
+		}
 
-		fmt.Printf("Resuming \"%s\" from entry #%d with new ID #%d\n", entry.Note, entry.ID, newId)
+		fmt.Printf("Resuming \"%s\" from entry #%d with new ID #%d\n", resumed.Note, entry.ID, resumed.ID)
 		return nil
 	})
-	commands.AddCommand([]string{"now"}, "show the current entry", "", func() error {
+	commands.AddCommand([]string{"now"}, "show the current entry", "", nil, func() error {
 		entry, err := state.GetCurrentEntry()
 		if err != nil {
 			return err
 		}
 
+		sheet := meta.CurrentSheet
 		if entry == nil {
 			fmt.Fprintf(os.Stderr, "*%s: not running\n", meta.CurrentSheet)
-			return nil
+		} else {
+			sheet = entry.Sheet
+			duration, _ := entry.Duration()
+			fmt.Printf("*%s: %s (%s)\n", entry.Sheet, utils.FormatDuration(duration), entry.Note)
+
+			plannedEnd, err := state.GetPlannedEnd(entry.ID)
+			if err != nil {
+				return err
+			} else if plannedEnd != nil {
+				fmt.Printf(
+					"until %s (%s remaining)\n",
+					plannedEnd.Format("15:04:05"),
+					utils.FormatDuration(plannedEnd.Sub(time.Now())),
+				)
+			}
 		}
 
-		duration, _ := entry.Duration()
-		fmt.Printf("*%s: %s (%s)\n", entry.Sheet, utils.FormatDuration(duration), entry.Note)
+		progress, err := getGoalProgress(state, sheet, time.Now(), cfg.WeekStart)
+		if err != nil {
+			return err
+		} else if progress != nil {
+			progress.Write(os.Stdout)
+		}
 		return nil
 	})
-	commands.AddCommand([]string{"edit"}, "edit an entry", "[--id (current/last)] [--start] [--end] [note]", func() error {
+	commands.AddCommand([]string{"edit"}, "edit an entry, or all entries of a day in your editor", "[note]", flagsNamed("id", "where-note", "day", "start", "end", "editor", "dry-run", "formatter", "formatter-opt"), func() error {
+		if input.Raw["editor"] == "true" {
+			day := input.Day
+			if day == (time.Time{}) {
+				day = time.Now()
+			}
+
+			all, err := state.GetAllEntries("")
+			if err != nil {
+				return err
+			}
+
+			var before []*types.Entry
+			for _, entry := range all {
+				if utils.SameDate(entry.Start, day) {
+					before = append(before, entry)
+				}
+			}
+
+			after, err := editEntriesInEditor(before, cfg.NoteEditor)
+			if err != nil {
+				return err
+			}
+
+			changes, err := diffEntries(before, after)
+			if err != nil {
+				return err
+			}
+			if changes.Empty() {
+				fmt.Println("nothing changed")
+				return nil
+			}
+
+			running, err := state.GetCurrentEntry()
+			if err != nil {
+				return err
+			}
+			for _, entry := range before {
+				if running != nil && entry.ID == running.ID {
+					// the running entry is being edited, so it's checked
+					// with the other edited entries
+					running = nil
+				}
+			}
+			if err := validateEntries(after, running); err != nil {
+				return err
+			}
+
+			writeEntryChanges(os.Stderr, changes, before)
+			if !utils.Confirm("apply these changes?", true) {
+				return nil
+			}
+
+			if err := state.ApplyChanges(changes.Inserts, changes.Updates, changes.Deletes); err != nil {
+				return err
+			}
+			runPostHooks(hookPostEdit, changes.Inserts...)
+			runPostHooks(hookPostEdit, changes.Updates...)
+			runPostHooks(hookPostKill, changes.Deletes...)
+			fmt.Printf(
+				"%d inserted, %d updated, %d deleted\n",
+				len(changes.Inserts),
+				len(changes.Updates),
+				len(changes.Deletes),
+			)
+			return nil
+		}
+
+		if batch {
+			entries, err := selectEntries()
+			if err != nil {
+				return err
+			}
+
+			if input.Start == (time.Time{}) && input.End == (time.Time{}) && input.Note == "" {
+				fmt.Println("nothing changed")
+				return nil
+			}
+
+			for _, entry := range entries {
+				if input.Start != (time.Time{}) {
+					entry.Start = input.Start
+				}
+				if input.End != (time.Time{}) {
+					end := input.End
+					entry.End = &end
+				}
+				if input.Note != "" {
+					entry.Note = input.Note
+				}
+			}
+			if err := validateEntries(entries, nil); err != nil {
+				return err
+			}
+
+			str := fmt.Sprintf("are you sure you want to change these %d entries?", len(entries))
+			if !confirmBatch(str, entries) {
+				return nil
+			}
+
+			if err := state.ApplyChanges(nil, entries, nil); err != nil {
+				return err
+			}
+			runPostHooks(hookPostEdit, entries...)
+			fmt.Printf("Changed %d entries.\n", len(entries))
+			return nil
+		}
+
 		entry, err := state.GetEntry(input.ID)
 		if err != nil {
 			return err
 		} else if entry == nil {
-			return fmt.Errorf("no entry with ID %d found", input.ID)
+			return notFoundErrorf("no entry with ID %d found", input.ID)
 		}
 
 		any := false
@@ -260,6 +732,7 @@ func main() {
 		); err != nil {
 			return err
 		}
+		runPostHooks(hookPostEdit, entry)
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 		fmt.Fprintln(w, "Id\tDay\tStart      End\tDuration\tNotes")
@@ -267,11 +740,55 @@ func main() {
 		return w.Flush()
 	})
 
-	commands.AddCommand([]string{"display"}, "show all entries in the given sheet", "[SHEET/all/full (current)]", func() error {
+	commands.AddCommand([]string{"move"}, "move entries to another sheet", "<sheet>", flagsNamed("id", "where-note", "day", "dry-run", "formatter", "formatter-opt"), func() error {
+		sheet := input.Note
+		if sheet == "" {
+			return usageErrorf("no sheet given")
+		} else if strings.Contains(sheet, " ") {
+			return usageErrorf("name cannot contain spaces")
+		}
+
+		var entries []*types.Entry
+		if batch {
+			entries, err = selectEntries()
+			if err != nil {
+				return err
+			}
+
+			str := fmt.Sprintf("are you sure you want to move these %d entries to sheet \"%s\"?", len(entries), sheet)
+			if !confirmBatch(str, entries) {
+				return nil
+			}
+		} else {
+			entry, err := state.GetEntry(input.ID)
+			if err != nil {
+				return err
+			} else if entry == nil {
+				return notFoundErrorf("no entry with ID %d found", input.ID)
+			}
+			entries = []*types.Entry{entry}
+		}
+
+		for _, entry := range entries {
+			entry.Sheet = sheet
+		}
+		if err := state.ApplyChanges(nil, entries, nil); err != nil {
+			return err
+		}
+		runPostHooks(hookPostEdit, entries...)
+
+		fmt.Printf("Moved %d entries to sheet \"%s\".\n", len(entries), sheet)
+		return nil
+	})
+
+	commands.AddCommand([]string{"display"}, "show all entries in the given sheet", "[SHEET/all/full (current, or all when selecting entries)]", flagsNamed("id", "where-note", "day", "start", "end", "filter", "formatter", "formatter-opt", "round", "round-mode", "round-per"), func() error {
 		sheet := input.Note
 		switch input.Note {
 		case "":
 			sheet = meta.CurrentSheet
+			if !sel.Empty() {
+				sheet = ""
+			}
 		case "all", "full": // TODO full /= all
 			sheet = ""
 		}
@@ -282,7 +799,27 @@ func main() {
 		}
 
 		if len(entries) == 0 {
-			return fmt.Errorf("Can't find sheet matching \"%s\"", sheet)
+			return notFoundErrorf("Can't find sheet matching \"%s\"", sheet)
+		}
+
+		if !sel.Empty() {
+			entries = sel.Select(entries)
+		}
+
+		// like report, only the entries that started between start and end
+		// are shown
+		if input.Start != (time.Time{}) || input.End != (time.Time{}) {
+			filtered := []*types.Entry{}
+			for _, entry := range entries {
+				if input.Start != (time.Time{}) && entry.Start.Before(input.Start) {
+					continue
+				} else if input.End != (time.Time{}) && !entry.Start.Before(input.End) {
+					continue
+				}
+
+				filtered = append(filtered, entry)
+			}
+			entries = filtered
 		}
 
 		if input.Filter != "" {
@@ -298,18 +835,81 @@ func main() {
 		}
 
 		return input.Formatter.Write(os.Stdout, &types.FormatterInput{
-			Sheet:   sheet,
-			Entries: entries[:],
+			Sheet:    sheet,
+			Entries:  entries[:],
+			Rounding: input.Rounding,
 		})
 	})
 
-	commands.AddCommand([]string{"sheet"}, "show sheets or change the current sheet", "[sheet]", func() error {
-		if strings.Contains(input.Note, " ") {
-			return errors.New("name cannot contain spaces")
-		} else if input.Note != "" {
-			if err := state.SwitchSheet(input.Note); err != nil {
-				return err
-			}
+	commands.AddCommand([]string{"report"}, "show the time spent per group", "[SHEET/all (all)]", flagsNamed("group-by", "start", "end", "filter", "formatter", "formatter-opt", "round", "round-mode", "round-per"), func() error {
+		sheet := input.Note
+		switch input.Note {
+		case "all", "full":
+			sheet = ""
+		}
+
+		entries, err := state.GetAllEntries(sheet)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 && sheet != "" {
+			return notFoundErrorf("Can't find sheet matching \"%s\"", sheet)
+		}
+
+		if input.Filter != "" {
+			filtered := []*types.Entry{}
+			for _, entry := range entries {
+				if !strings.Contains(entry.Note, input.Filter) {
+					continue
+				}
+
+				filtered = append(filtered, entry)
+			}
+			entries = filtered
+		}
+
+		report, err := BuildReport(entries, input.GroupBy, input.Start, input.End, input.Rounding)
+		if err != nil {
+			return err
+		}
+
+		return input.Formatter.WriteReport(os.Stdout, report)
+	})
+
+	commands.AddCommand([]string{"stats"}, "show statistics about the tracked time", "[SHEET/all (all)]", flagsNamed("start", "end", "formatter", "formatter-opt"), func() error {
+		sheet := input.Note
+		switch input.Note {
+		case "all", "full":
+			sheet = ""
+		}
+
+		entries, err := state.GetAllEntries(sheet)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 && sheet != "" {
+			return notFoundErrorf("Can't find sheet matching \"%s\"", sheet)
+		}
+
+		schedule, err := config.LoadSchedule()
+		if err != nil {
+			return err
+		}
+
+		stats := BuildStats(entries, schedule, input.Start, input.End, time.Now())
+		return input.Formatter.WriteStats(os.Stdout, stats)
+	})
+
+	commands.AddCommand([]string{"sheet"}, "show sheets or change the current sheet", "[sheet]", flagsNamed("round", "round-mode", "round-per", "dry-run", "formatter", "formatter-opt"), func() error {
+		if strings.Contains(input.Note, " ") {
+			return usageErrorf("name cannot contain spaces")
+		} else if input.Note != "" {
+			if err := state.SwitchSheet(input.Note); err != nil {
+				return err
+			}
+			runSheetSwitchHook(input.Note, meta.CurrentSheet)
 			fmt.Printf("Switching to sheet \"%s\"\n", input.Note)
 			return nil
 		}
@@ -322,19 +922,30 @@ func main() {
 		foundCurrent := false
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		printInfo := func(prefix, sheet string, running, today, total time.Duration) {
+		printInfo := func(prefix, sheet string, running, today, total time.Duration) error {
+			goal, left := "", ""
+			progress, err := getGoalProgress(state, sheet, time.Now(), cfg.WeekStart)
+			if err != nil {
+				return err
+			} else if progress != nil {
+				goal, left = progress.Summary()
+			}
+
 			fmt.Fprintf(
 				w,
-				"%s%s\t%s\t%s\t%s\n",
+				"%s%s\t%s\t%s\t%s\t%s\t%s\n",
 				prefix,
 				sheet,
 				utils.FormatDuration(running),
 				utils.FormatDuration(today),
 				utils.FormatDuration(total),
+				goal,
+				left,
 			)
+			return nil
 		}
 
-		fmt.Fprintf(w, " Timesheet\tRunning\tToday\tTotal Time\n")
+		fmt.Fprintf(w, " Timesheet\tRunning\tToday\tTotal Time\tGoal\tRemaining\n")
 		for _, sheet := range sheets {
 			curr, last := sheet == meta.CurrentSheet, sheet == meta.LastSheet
 
@@ -351,39 +962,255 @@ func main() {
 				return err
 			}
 
-			running := utils.SumDuration(entries, func(e *types.Entry) bool {
+			running := input.Rounding.Sum(entries, func(e *types.Entry) bool {
 				_, running := e.Duration()
 				return running
 			})
-			today := utils.SumDuration(entries, func(e *types.Entry) bool {
+			today := input.Rounding.Sum(entries, func(e *types.Entry) bool {
 				return utils.SameDate(time.Now(), e.Start)
 			})
-			total := utils.SumDuration(entries, func(e *types.Entry) bool {
+			total := input.Rounding.Sum(entries, func(e *types.Entry) bool {
 				return true
 			})
 
-			if running == 0 && today == 0 {
-				for _, entry := range entries {
-					duration, isRunning := entry.Duration()
-					if isRunning {
-						running += duration
-					}
+			if err := printInfo(prefix, sheet, running, today, total); err != nil {
+				return err
+			}
+		}
+
+		if !foundCurrent {
+			if err := printInfo("*", meta.CurrentSheet, 0, 0, 0); err != nil {
+				return err
+			}
+		}
+
+		return w.Flush()
+	})
+
+	commands.AddCommand([]string{"goals"}, "show or set the daily and weekly goals of sheets", "[SHEET (current)/all (all)]", flagsNamed("daily", "weekly", "dry-run", "formatter", "formatter-opt"), func() error {
+		if input.Raw["daily"] != "" || input.Raw["weekly"] != "" {
+			sheet := input.Note
+			if sheet == "" {
+				sheet = meta.CurrentSheet
+			}
+
+			goal, err := state.GetGoal(sheet)
+			if err != nil {
+				return err
+			}
+
+			daily, weekly := input.Daily, input.Weekly
+			if goal != nil {
+				if input.Raw["daily"] == "" {
+					daily = goal.Daily
+				}
+				if input.Raw["weekly"] == "" {
+					weekly = goal.Weekly
 				}
 			}
 
-			printInfo(prefix, sheet, running, today, total)
+			if err := state.SetGoal(sheet, daily, weekly); err != nil {
+				return err
+			}
+			fmt.Printf(
+				"Goals of sheet \"%s\": %s per day, %s per week\n",
+				sheet,
+				utils.FormatDuration(daily),
+				utils.FormatDuration(weekly),
+			)
+			return nil
 		}
 
-		if !foundCurrent {
-			printInfo("*", meta.CurrentSheet, 0, 0, 0)
+		goals, err := state.GetAllGoals()
+		if err != nil {
+			return err
+		}
+
+		any := false
+		for _, goal := range goals {
+			if input.Note != "" && input.Note != "all" && input.Note != goal.Sheet {
+				continue
+			}
+
+			entries, err := state.GetAllEntries(goal.Sheet)
+			if err != nil {
+				return err
+			}
+
+			if any {
+				fmt.Println()
+			}
+			any = true
+
+			if err := writeGoalWeek(os.Stdout, goal, entries, time.Now(), cfg.WeekStart); err != nil {
+				return err
+			}
+		}
+
+		if !any {
+			return notFoundErrorf("no goals")
+		}
+		return nil
+	})
+
+	commands.AddCommand([]string{"balance"}, "show the overtime balance against the schedule", "[SHEET/all (all)]", flagsNamed("since", "end"), func() error {
+		sheet := input.Note
+		switch input.Note {
+		case "all", "full":
+			sheet = ""
+		}
+
+		entries, err := state.GetAllEntries(sheet)
+		if err != nil {
+			return err
+		} else if len(entries) == 0 {
+			return notFoundErrorf("no entries")
+		}
+
+		schedule, err := config.LoadSchedule()
+		if err != nil {
+			return err
+		}
+
+		since := input.Since
+		if since == (time.Time{}) {
+			since = entries[0].Start
+		}
+		until := input.End
+		if until == (time.Time{}) {
+			until = time.Now()
+		}
+
+		weeks := computeBalance(schedule, entries, since, until, cfg.WeekStart)
+		return writeBalance(os.Stdout, weeks)
+	})
+
+	getGaps := func() ([]gap, error) {
+		day := input.Day
+		if day == (time.Time{}) {
+			day = time.Now()
+		}
+
+		entries, err := state.GetAllEntries("")
+		if err != nil {
+			return nil, err
+		}
+
+		schedule, err := config.LoadSchedule()
+		if err != nil {
+			return nil, err
+		}
+
+		return findGaps(schedule, entries, day, time.Now(), input.Min), nil
+	}
+
+	commands.AddCommand([]string{"gaps"}, "show the untracked time between entries", "", flagsNamed("day", "min"), func() error {
+		gaps, err := getGaps()
+		if err != nil {
+			return err
+		} else if len(gaps) == 0 {
+			fmt.Println("no gaps")
+			return nil
 		}
 
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Day\tStart      End\tDuration")
+
+		var total time.Duration
+		for _, g := range gaps {
+			total += g.Duration()
+			fmt.Fprintf(
+				w,
+				"%s\t%s - %s\t%s\n",
+				g.Start.Format("Mon Jan 2, 2006"),
+				g.Start.Format("15:04:05"),
+				g.End.Format("15:04:05"),
+				utils.FormatDuration(g.Duration()),
+			)
+		}
+		fmt.Fprintf(w, "\t\t \n")
+		fmt.Fprintf(w, "\t\t%s\n", utils.FormatDuration(total))
+
 		return w.Flush()
 	})
 
-	commands.AddCommand([]string{"kill"}, "delete an entry or sheet", "--id <id>\n\t<sheet>", func() error {
-		idEmpty := input.Raw["id"] == "0"
-		if idEmpty && input.Note != "" { // kill timesheet
+	commands.AddCommand([]string{"fill"}, "create entries for the untracked time between entries", "", flagsNamed("day", "min", "dry-run", "formatter", "formatter-opt"), func() error {
+		gaps, err := getGaps()
+		if err != nil {
+			return err
+		} else if len(gaps) == 0 {
+			fmt.Println("no gaps")
+			return nil
+		}
+
+		var entries []*types.Entry
+		for i, g := range gaps {
+			fmt.Fprintf(
+				os.Stderr,
+				"gap %d/%d: %s - %s (%s)\n",
+				i+1,
+				len(gaps),
+				g.Start.Format("15:04:05"),
+				g.End.Format("15:04:05"),
+				utils.FormatDuration(g.Duration()),
+			)
+
+			sheet, err := utils.Prompt(fmt.Sprintf("sheet (%s, \"-\" to skip): ", meta.CurrentSheet))
+			if err != nil {
+				return err
+			}
+			sheet = strings.TrimSpace(sheet)
+			if sheet == "-" {
+				continue
+			} else if sheet == "" {
+				sheet = meta.CurrentSheet
+			} else if strings.Contains(sheet, " ") {
+				return usageErrorf("name cannot contain spaces")
+			}
+
+			note, err := utils.Prompt("note: ")
+			if err != nil {
+				return err
+			}
+
+			end := g.End
+			entries = append(entries, &types.Entry{
+				Start: g.Start,
+				End:   &end,
+				Sheet: sheet,
+				Note:  strings.TrimSpace(note),
+			})
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("nothing changed")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stderr, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "Sheet\tId\tDay\tStart      End\tDuration\tNotes")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t", entry.Sheet)
+			writeEntry(entry, w)
+		}
+		w.Flush()
+
+		str := fmt.Sprintf("create %d entries?", len(entries))
+		if !utils.Confirm(str, true) {
+			return nil
+		}
+
+		if err := state.AddEntries(entries); err != nil {
+			return err
+		}
+		runPostHooks(hookPostEdit, entries...)
+		fmt.Printf("Created %d entries.\n", len(entries))
+		return nil
+	})
+
+	commands.AddCommand([]string{"kill"}, "delete entries or a sheet", "<sheet>\n--id <ids>\n--where-note <note> [--day <time>]", flagsNamed("id", "where-note", "day", "dry-run", "formatter", "formatter-opt"), func() error {
+		idEmpty := input.Raw["id"] == "" || input.Raw["id"] == "0"
+		if idEmpty && !batch && input.Note != "" { // kill timesheet
 			sheets, err := state.GetAllSheets()
 			if err != nil {
 				return err
@@ -396,7 +1223,7 @@ func main() {
 				}
 			}
 			if !has {
-				return fmt.Errorf("no sheet with name %s found", input.Note)
+				return notFoundErrorf("no sheet with name %s found", input.Note)
 			}
 
 			str := fmt.Sprintf("are you sure you want to delete sheet \"%s\"?", input.Note)
@@ -404,27 +1231,52 @@ func main() {
 				return nil
 			}
 
+			entries, err := state.GetAllEntries(input.Note)
+			if err != nil {
+				return err
+			}
+
 			if err := state.RemoveSheet(input.Note); err != nil {
 				return err
 			}
+			runPostHooks(hookPostKill, entries...)
 			fmt.Println("it's killed")
 			return nil
 		}
 
+		if batch {
+			entries, err := selectEntries()
+			if err != nil {
+				return err
+			}
+
+			str := fmt.Sprintf("are you sure you want to delete these %d entries?", len(entries))
+			if !confirmBatch(str, entries) {
+				return nil
+			}
+
+			if err := state.ApplyChanges(nil, nil, entries); err != nil {
+				return err
+			}
+			runPostHooks(hookPostKill, entries...)
+			fmt.Printf("%d entries killed\n", len(entries))
+			return nil
+		}
+
 		var entry *types.Entry
 		if idEmpty {
 			entry, err = state.GetLastEntry(meta.CurrentSheet)
 			if err != nil {
 				return err
 			} else if entry == nil {
-				return errors.New("no entries")
+				return notFoundErrorf("no entries")
 			}
 		} else {
 			entry, err = state.GetEntry(input.ID)
 			if err != nil {
 				return err
 			} else if entry == nil {
-				return fmt.Errorf("no entry with ID %d found", input.ID)
+				return notFoundErrorf("no entry with ID %d found", input.ID)
 			}
 		}
 
@@ -436,11 +1288,12 @@ func main() {
 		if err := state.RemoveEntry(entry.ID); err != nil {
 			return err
 		}
+		runPostHooks(hookPostKill, entry)
 		fmt.Println("it's killed")
 		return nil
 	})
 
-	commands.AddCommand([]string{"idle"}, "show the time since you last checked out", "[sheet]", func() error {
+	commands.AddCommand([]string{"idle"}, "show the time since you last checked out", "[sheet]", nil, func() error {
 		sheet := input.Note
 		switch sheet {
 		case "":
@@ -456,14 +1309,14 @@ func main() {
 
 		last := utils.GetNth(entries, len(entries)-1)
 		if last == nil {
-			return errors.New("no entries")
+			return notFoundErrorf("no entries")
 		}
 
 		var duration time.Duration
 		if last.End == nil {
 			beforeLast := utils.GetNth(entries, len(entries)-2)
 			if beforeLast == nil {
-				return errors.New("no entry before current one")
+				return notFoundErrorf("no entry before current one")
 			}
 			duration = last.Start.Sub(*beforeLast.End)
 		} else {
@@ -474,42 +1327,188 @@ func main() {
 		return nil
 	})
 
-	commands.AddCommand([]string{"help"}, "show usage (of a command)", "[command]", func() error {
+	commands.AddCommand([]string{"config"}, "show or change the settings in config.yml", "[list]\nget <key>\nset <key> <value>", nil, func() error {
+		action, rest := input.Note, ""
+		if i := strings.Index(action, " "); i >= 0 {
+			action, rest = action[:i], strings.TrimSpace(action[i+1:])
+		}
+
+		switch action {
+		case "", "list":
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			for _, option := range config.Options {
+				value, _ := cfg.Get(option.Key)
+				fmt.Fprintf(w, "%s:\t%s\t%s\n", option.Key, value, option.Description)
+			}
+			return w.Flush()
+
+		case "get":
+			value, err := cfg.Get(rest)
+			if err != nil {
+				return withCode(exitConfig, err)
+			}
+			fmt.Println(value)
+			return nil
+
+		case "set":
+			key, value := rest, ""
+			if i := strings.Index(key, " "); i >= 0 {
+				key, value = key[:i], strings.TrimSpace(key[i+1:])
+			}
+			if err := config.Write(key, value); err != nil {
+				return withCode(exitConfig, err)
+			}
+			fmt.Printf("Set %s to \"%s\".\n", key, value)
+			return nil
+		}
+
+		return usageErrorf("unknown config action \"%s\"", action)
+	})
+
+	commands.AddCommand([]string{"formatters"}, "list the formatters and their options", "", nil, func() error {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		for _, r := range formatters.All() {
+			name := r.Name
+			if len(r.Aliases) > 0 {
+				name += " (" + strings.Join(r.Aliases, ", ") + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\n", name, r.Description)
+
+			for _, option := range r.Options {
+				fmt.Fprintf(w, "  %s=<value>\t%s (default %s)\n", option.Name, option.Description, option.Default)
+			}
+		}
+		return w.Flush()
+	})
+
+	commands.AddCommand([]string{"completion"}, "print the shell completion script", "bash/zsh/fish", nil, func() error {
+		script, has := completionScripts[input.Note]
+		if !has {
+			return usageErrorf("no completion for shell \"%s\"", input.Note)
+		}
+
+		fmt.Print(script)
+		return nil
+	})
+
+	commands.AddCommand([]string{completeCommand}, "print the completion candidates", "-- [words]", nil, func() error {
+		c := &completer{state: state, aliases: aliases}
+		candidates, err := c.Complete(completionWords(os.Args))
+		if err != nil {
+			return err
+		}
+
+		writeCandidates(candidates)
+		return nil
+	}).Hidden = true
+
+	commands.AddCommand([]string{"help"}, "show usage (of a command)", "[command]", nil, func() error {
 		if input.Note == "" {
 			usage()
 			return nil
 		}
 
+		for _, alias := range aliases {
+			if alias.Name == input.Note {
+				fmt.Fprintf(os.Stderr, "%s: alias for %s\n", alias.Name, alias.Command)
+				return nil
+			}
+		}
+
 		cmds := commands.GetByPrefix(input.Note)
 		if len(cmds) == 0 {
-			return fmt.Errorf("unknown command %s", input.Note)
+			if pluginPath, ok := findPlugin(input.Note); ok {
+				fmt.Fprintf(os.Stderr, "%s: plugin, runs %s\n", input.Note, pluginPath)
+				return nil
+			}
+			return usageErrorf("unknown command %s", input.Note)
 		} else if len(cmds) > 1 {
-			return errors.New("ambigious command")
+			return usageErrorf("ambigious command")
 		}
 
-		cmd := cmds[0]
-		fmt.Fprintf(os.Stderr, "%s: %s\n\t%s\n", strings.Join(cmd.Names, ", "), cmd.Description, cmd.Usage)
+		cmds[0].WriteHelp(os.Stderr)
 
 		return nil
 	})
 
+	for _, alias := range aliases {
+		if commands.GetByName(alias.Name) != nil {
+			fail(withCode(exitConfig, fmt.Errorf("alias %s shadows a built-in command", alias.Name)), input.Output)
+		}
+	}
+
+	if i := commandIndex(args); i >= 0 && len(commands.GetByPrefix(args[i])) == 0 {
+		if pluginPath, ok := findPlugin(args[i]); ok {
+			entryID := ""
+			if currentEntry != nil {
+				entryID = fmt.Sprint(currentEntry.ID)
+			}
+
+			// plugins have flags of their own and write to the database
+			// themselves
+			state.RollbackDryRun()
+
+			code, err := runPlugin(pluginPath, args[i+1:], map[string]string{
+				"GOT_DATABASE": dbPath,
+				"GOT_SHEET":    meta.CurrentSheet,
+				"GOT_ENTRY_ID": entryID,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(code)
+		}
+	}
+
+	if inputErr != nil {
+		failUsage(inputErr, nil, input.Output)
+	}
+
 	if input.Command == "" {
 		usage()
+		os.Exit(exitUsage)
 	}
 
 	cmds := commands.GetByPrefix(input.Command)
 	if len(cmds) == 0 {
-		fmt.Fprintf(os.Stderr, "unknown command %s\n", input.Command)
-		usage()
-		return
+		failUsage(fmt.Errorf("unknown command %s", input.Command), usage, input.Output)
 	} else if len(cmds) > 1 {
-		fmt.Fprintln(os.Stderr, "ambigious command")
-		usage()
-		return
+		failUsage(errors.New("ambigious command"), usage, input.Output)
+	}
+
+	if err := cmds[0].CheckFlags(input.Flags, globalFlags); err != nil {
+		failUsage(err, func() { cmds[0].WriteHelp(os.Stderr) }, input.Output)
+	}
+	if err := checkFormatterFlags(cmds[0], input.Flags, dryRun); err != nil {
+		failUsage(err, func() { cmds[0].WriteHelp(os.Stderr) }, input.Output)
+	}
+	// only the commands that take --formatter use the formatter, the ones that
+	// change entries only in a dry run
+	if cmds[0].HasFlag("formatter") && (dryRun || !cmds[0].HasFlag("dry-run")) {
+		formatter, err := input.NewFormatter()
+		if err != nil {
+			failUsage(err, func() { cmds[0].WriteHelp(os.Stderr) }, input.Output)
+		}
+		input.Formatter = formatter
 	}
 
 	if err := cmds[0].Fn(); err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n\n", err)
-		usage()
+		if exitCode(err) == exitUsage {
+			failUsage(err, func() { cmds[0].WriteHelp(os.Stderr) }, input.Output)
+		}
+		fail(err, input.Output)
+	}
+
+	if dryRun {
+		after, err := takeSnapshot(state)
+		if err != nil {
+			fail(withCode(exitDatabase, err), input.Output)
+		}
+		if err := writeDryRun(os.Stdout, os.Stderr, before, after, input.Formatter, input.Rounding); err != nil {
+			fail(err, input.Output)
+		}
+		if err := state.RollbackDryRun(); err != nil {
+			fail(withCode(exitDatabase, err), input.Output)
+		}
 	}
 }
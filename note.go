@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"got/types"
+	"got/utils"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxRecentNotes is the amount of recent notes offered when prompting for a
+// note.
+const maxRecentNotes = 9
+
+// recentNotes returns the distinct non empty notes of the entries, most
+// recent first.
+func recentNotes(entries []*types.Entry, max int) []string {
+	var res []string
+	seen := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0 && len(res) < max; i-- {
+		note := entries[i].Note
+		if note == "" || seen[note] {
+			continue
+		}
+		seen[note] = true
+		res = append(res, note)
+	}
+	return res
+}
+
+// promptNote asks the user for a note, offering the recent notes of the sheet
+// to pick from by number.
+func promptNote(state *State, sheet string) (string, error) {
+	if !utils.IsTerminal() {
+		return "", errors.New("a note is required, give one with --note when not running in a terminal")
+	}
+
+	entries, err := state.GetAllEntries(sheet)
+	if err != nil {
+		return "", err
+	}
+
+	notes := recentNotes(entries, maxRecentNotes)
+	if len(notes) > 0 {
+		fmt.Fprintf(os.Stderr, "recent notes in sheet \"%s\":\n", sheet)
+		for i, note := range notes {
+			fmt.Fprintf(os.Stderr, "\t%d: %s\n", i+1, note)
+		}
+	}
+
+	for {
+		prompt := "note: "
+		if len(notes) > 0 {
+			prompt = "note (or number of a recent note): "
+		}
+
+		str, err := utils.Prompt(prompt)
+		if err != nil {
+			return "", errors.New("a note is required")
+		}
+
+		str = strings.TrimSpace(str)
+		if n, err := strconv.Atoi(str); err == nil && n >= 1 && n <= len(notes) {
+			return notes[n-1], nil
+		} else if str != "" {
+			return str, nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"got/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecentNotes(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	var entries []*types.Entry
+	for i, note := range []string{"a", "b", "", "a", "c", "b", "d"} {
+		entries = append(entries, &types.Entry{Start: start.Add(time.Duration(i) * time.Hour), Sheet: "main", Note: note})
+	}
+
+	tests := []struct {
+		max      int
+		expected []string
+	}{
+		{9, []string{"d", "b", "c", "a"}},
+		{2, []string{"d", "b"}},
+		{0, nil},
+	}
+
+	for _, test := range tests {
+		if notes := recentNotes(entries, test.max); strings.Join(notes, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("the %d recent notes are %q, expected %q", test.max, notes, test.expected)
+		}
+	}
+
+	if notes := recentNotes(nil, 9); len(notes) != 0 {
+		t.Errorf("the recent notes of no entries are %q", notes)
+	}
+}
+
+func TestRecentNotesOfSheet(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	if err := state.AddEntries([]*types.Entry{
+		{Start: start, End: &end, Sheet: "main", Note: "planning"},
+		{Start: end, End: &end, Sheet: "home", Note: "dishes"},
+		{Start: end.Add(time.Hour), Sheet: "main", Note: "review"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the notes are offered from the entries of the sheet, like promptNote
+	// does
+	entries, err := state.GetAllEntries("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes := recentNotes(entries, maxRecentNotes); strings.Join(notes, ",") != "review,planning" {
+		t.Errorf("the recent notes of main are %q, expected review and planning", notes)
+	}
+}
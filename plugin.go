@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pluginPrefix is the prefix of the executables on $PATH that are run for
+// commands that are not built in, like git does.
+const pluginPrefix = "got-"
+
+// findPlugin returns the path of the executable for the given command name.
+func findPlugin(name string) (string, bool) {
+	if name == "" || strings.ContainsRune(name, os.PathSeparator) {
+		return "", false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + name)
+	return path, err == nil
+}
+
+// discoverPlugins returns the names of all plugins on $PATH.
+func discoverPlugins() []string {
+	seen := make(map[string]bool)
+	var res []string
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasPrefix(name, pluginPrefix) || file.IsDir() || file.Mode()&0111 == 0 {
+				continue
+			}
+
+			name = strings.TrimPrefix(name, pluginPrefix)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				res = append(res, name)
+			}
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// runPlugin runs the plugin with the given arguments and environment variables
+// added to the environment of got, and returns its exit code.
+func runPlugin(path string, args []string, env map[string]string) (int, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	} else if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"got/types"
+	"sort"
+	"time"
+)
+
+type reportGrouping struct {
+	// keys returns the keys of the groups the entry belongs to, an entry can
+	// be in multiple groups (eg. when it has multiple tags).
+	keys func(e *types.Entry) []string
+	// chronological groupings keep the order of the entries, the others are
+	// sorted on duration.
+	chronological bool
+}
+
+var reportGroupings = map[string]reportGrouping{
+	"sheet": {
+		keys: func(e *types.Entry) []string { return []string{e.Sheet} },
+	},
+	"day": {
+		keys: func(e *types.Entry) []string {
+			return []string{e.Start.Format("Mon Jan 2, 2006")}
+		},
+		chronological: true,
+	},
+	"week": {
+		keys: func(e *types.Entry) []string {
+			year, week := e.Start.ISOWeek()
+			return []string{fmt.Sprintf("%d-W%02d", year, week)}
+		},
+		chronological: true,
+	},
+	"month": {
+		keys: func(e *types.Entry) []string {
+			return []string{e.Start.Format("January 2006")}
+		},
+		chronological: true,
+	},
+	"note": {
+		keys: func(e *types.Entry) []string {
+			if e.Note == "" {
+				return []string{"(no note)"}
+			}
+			return []string{e.Note}
+		},
+	},
+	"tag": {
+		keys: func(e *types.Entry) []string {
+			tags := e.Tags()
+			if len(tags) == 0 {
+				return []string{"(no tag)"}
+			}
+			return tags
+		},
+	},
+}
+
+func percentage(d, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(d) / float64(total) * 100
+}
+
+func buildReportGroups(entries []*types.Entry, groupBy []string, total time.Duration, r *types.Rounding) []*types.ReportGroup {
+	if len(groupBy) == 0 {
+		return nil
+	}
+	grouping := reportGroupings[groupBy[0]]
+
+	var res []*types.ReportGroup
+	members := make(map[string][]*types.Entry)
+	for _, entry := range entries {
+		seen := make(map[string]bool)
+		for _, key := range grouping.keys(entry) {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if _, has := members[key]; !has {
+				res = append(res, &types.ReportGroup{Key: key})
+			}
+			members[key] = append(members[key], entry)
+		}
+	}
+
+	for _, group := range res {
+		group.Duration = r.Sum(members[group.Key], func(*types.Entry) bool { return true })
+		group.Percentage = percentage(group.Duration, total)
+		group.Groups = buildReportGroups(members[group.Key], groupBy[1:], total, r)
+	}
+
+	if !grouping.chronological {
+		sort.SliceStable(res, func(i, j int) bool {
+			return res[i].Duration > res[j].Duration
+		})
+	}
+
+	return res
+}
+
+// BuildReport groups the given entries that started between start and end
+// (zero values meaning unbounded) on the given groupings, in order.  The
+// durations are rounded with the given rounding, which can be nil.
+func BuildReport(entries []*types.Entry, groupBy []string, start, end time.Time, r *types.Rounding) (*types.Report, error) {
+	if len(groupBy) == 0 {
+		return nil, usageErrorf("no grouping given")
+	}
+	for _, name := range groupBy {
+		if _, has := reportGroupings[name]; !has {
+			return nil, usageErrorf("unknown grouping %s", name)
+		}
+	}
+
+	report := &types.Report{
+		GroupBy: groupBy,
+	}
+	if start != (time.Time{}) {
+		report.Start = &start
+	}
+	if end != (time.Time{}) {
+		report.End = &end
+	}
+
+	var filtered []*types.Entry
+	for _, entry := range entries {
+		if report.Start != nil && entry.Start.Before(start) {
+			continue
+		} else if report.End != nil && !entry.Start.Before(end) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	report.Total = r.Sum(filtered, func(*types.Entry) bool { return true })
+	report.Groups = buildReportGroups(filtered, groupBy, report.Total, r)
+
+	return report, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	entries := []*types.Entry{
+		testEntry(5, time.Hour, "work", "a"),
+		testEntry(6, 30*time.Minute, "work", "b"),
+		testEntry(6, 2*time.Hour, "home", "a"),
+		testEntry(7, 15*time.Minute, "work", "a"),
+	}
+
+	report, err := BuildReport(entries, []string{"sheet", "note"}, time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local), time.Date(2026, 1, 7, 0, 0, 0, 0, time.Local), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Total != 210*time.Minute {
+		t.Errorf("the total is %s, expected 3h30m", report.Total)
+	}
+	if len(report.Groups) != 2 {
+		t.Fatalf("there are %d groups, expected 2", len(report.Groups))
+	}
+	home, work := report.Groups[0], report.Groups[1]
+	if home.Key != "home" || home.Duration != 2*time.Hour {
+		t.Errorf("the first group is %s with %s, expected home with 2h", home.Key, home.Duration)
+	}
+	if work.Key != "work" || work.Duration != 90*time.Minute || len(work.Groups) != 2 {
+		t.Errorf("the second group is %s with %s in %d groups, expected work with 1h30m in 2 groups", work.Key, work.Duration, len(work.Groups))
+	}
+
+	for _, groupBy := range [][]string{nil, {"sheet", "color"}} {
+		if _, err := BuildReport(entries, groupBy, time.Time{}, time.Time{}, nil); err == nil {
+			t.Errorf("grouping on %q gave no error", groupBy)
+		} else if exitCode(err) != exitUsage {
+			t.Errorf("grouping on %q gave exit code %d, expected %d", groupBy, exitCode(err), exitUsage)
+		}
+	}
+}
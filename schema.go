@@ -14,6 +14,24 @@ var schemaEntries = []string{
 	`insert into meta(key, value) values("last_sheet", "main")`,
 }
 
+// migrations are run once each, in order, also on databases created by
+// timetrap.  The number of migrations that ran is the schema_version in meta,
+// so new migrations are only ever added at the end.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS goals (sheet varchar(255) NOT NULL PRIMARY KEY, daily integer NOT NULL DEFAULT 0, weekly integer NOT NULL DEFAULT 0);`,
+	`CREATE TABLE IF NOT EXISTS planned_ends (entry_id integer NOT NULL PRIMARY KEY, end timestamp NOT NULL);`,
+
+	// times are stored in UTC with a "+00:00" offset.  older versions of got
+	// stored them with the offset of the local time zone, which breaks sorting
+	// on them when the zone changes, and timetrap stores them without an
+	// offset in the local time of the system, so both are converted to UTC.
+	`UPDATE entries SET start = strftime('%Y-%m-%d %H:%M:%f', start) || '+00:00' WHERE (start LIKE '%+__:__' OR start LIKE '%-__:__') AND start NOT LIKE '%+00:00';`,
+	`UPDATE entries SET end = strftime('%Y-%m-%d %H:%M:%f', end) || '+00:00' WHERE (end LIKE '%+__:__' OR end LIKE '%-__:__') AND end NOT LIKE '%+00:00';`,
+	`UPDATE planned_ends SET end = strftime('%Y-%m-%d %H:%M:%f', end) || '+00:00' WHERE (end LIKE '%+__:__' OR end LIKE '%-__:__') AND end NOT LIKE '%+00:00';`,
+	`UPDATE entries SET start = strftime('%Y-%m-%d %H:%M:%f', start, 'utc') || '+00:00' WHERE start NOT LIKE '%+__:__' AND start NOT LIKE '%-__:__';`,
+	`UPDATE entries SET end = strftime('%Y-%m-%d %H:%M:%f', end, 'utc') || '+00:00' WHERE end NOT LIKE '%+__:__' AND end NOT LIKE '%-__:__';`,
+}
+
 func runSchema(db *sql.DB) error {
 	tx, err := db.Begin()
 	if err != nil {
@@ -29,3 +47,37 @@ func runSchema(db *sql.DB) error {
 
 	return tx.Commit()
 }
+
+func runMigrations(db *sql.DB) error {
+	// databases of timetrap and of older versions of got have no version
+	var version int
+	err := db.QueryRow("SELECT value FROM meta WHERE key='schema_version'").Scan(&version)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	} else if version >= len(migrations) {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range migrations[version:] {
+		if _, err := tx.Exec(entry); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM meta WHERE key='schema_version'"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO meta(key, value) VALUES('schema_version', ?)", len(migrations)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"got/types"
+	"got/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIDRange is the maximum amount of IDs in a single range, to guard against
+// typos like "1-1000000".
+const maxIDRange = 10000
+
+// parseIDs parses a comma separated list of IDs and ranges of IDs, like
+// "3,5,10-14".
+func parseIDs(str string) ([]uint64, error) {
+	var res []uint64
+
+	for _, part := range strings.Split(str, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.ParseUint(bounds[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %s", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.ParseUint(bounds[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid ID %s", bounds[1])
+			} else if last < first || last-first >= maxIDRange {
+				return nil, fmt.Errorf("invalid ID range %s", part)
+			}
+		}
+
+		for id := first; id <= last; id++ {
+			res = append(res, id)
+		}
+	}
+
+	return res, nil
+}
+
+// selector selects entries on their IDs, note and day, an empty field matches
+// every entry.
+type selector struct {
+	IDs  []uint64
+	Note string
+	Day  time.Time
+}
+
+func (s *selector) Empty() bool {
+	return len(s.IDs) == 0 && s.Note == "" && s.Day == (time.Time{})
+}
+
+func (s *selector) Match(e *types.Entry) bool {
+	if len(s.IDs) > 0 {
+		found := false
+		for _, id := range s.IDs {
+			if id == e.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if s.Note != "" && !strings.Contains(e.Note, s.Note) {
+		return false
+	}
+	if s.Day != (time.Time{}) && !utils.SameDate(s.Day, e.Start) {
+		return false
+	}
+
+	return true
+}
+
+func (s *selector) Select(entries []*types.Entry) []*types.Entry {
+	var res []*types.Entry
+	for _, entry := range entries {
+		if s.Match(entry) {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
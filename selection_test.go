@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestParseIDs(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{"", "[]"},
+		{"3", "[3]"},
+		{"3,5,10-14", "[3 5 10 11 12 13 14]"},
+		{" 1 , 2,, ", "[1 2]"},
+		{"7-7", "[7]"},
+	}
+
+	for _, test := range tests {
+		ids, err := parseIDs(test.str)
+		if err != nil {
+			t.Errorf("parseIDs(%s): %s", test.str, err)
+		} else if str := fmt.Sprint(ids); str != test.expected {
+			t.Errorf("parseIDs(%s) = %s, expected %s", test.str, str, test.expected)
+		}
+	}
+
+	for _, str := range []string{"a", "1-b", "5-3", "1-100000", "-3"} {
+		if ids, err := parseIDs(str); err == nil {
+			t.Errorf("parseIDs(%s) = %v, expected an error", str, ids)
+		}
+	}
+}
+
+func TestSelector(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 1, d, 9, 0, 0, 0, time.Local)
+	}
+	entries := []*types.Entry{
+		{ID: 1, Start: day(5), Note: "standup"},
+		{ID: 2, Start: day(5), Note: "review"},
+		{ID: 3, Start: day(6), Note: "standup"},
+	}
+
+	tests := []struct {
+		selector selector
+		expected string
+	}{
+		{selector{}, "[1 2 3]"},
+		{selector{IDs: []uint64{1, 3}}, "[1 3]"},
+		{selector{Note: "stand"}, "[1 3]"},
+		{selector{Day: day(5)}, "[1 2]"},
+		{selector{Note: "standup", Day: day(6)}, "[3]"},
+		{selector{IDs: []uint64{2}, Note: "standup"}, "[]"},
+	}
+
+	for _, test := range tests {
+		var ids []uint64
+		for _, entry := range test.selector.Select(entries) {
+			ids = append(ids, entry.ID)
+		}
+		if str := fmt.Sprint(ids); str != test.expected {
+			t.Errorf("%+v selects %s, expected %s", test.selector, str, test.expected)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"database/sql"
-	"errors"
 	"got/types"
 	"strconv"
 	"time"
@@ -15,8 +14,18 @@ func scanEntry(s interface {
 	return e, s.Scan(&e.ID, &e.Note, &e.Start, &e.End, &e.Sheet)
 }
 
+// queryer runs queries on the database or in a transaction.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type State struct {
 	db *sql.DB
+	// tx is the running transaction, of a dry run or of transaction, all
+	// queries run in it when it is set.
+	tx *sql.Tx
 }
 
 type Meta struct {
@@ -33,7 +42,7 @@ func MakeState(db *sql.DB) (*State, error) {
 
 func (s *State) GetMeta() (*Meta, error) {
 	getMeta := func() (map[string]string, error) {
-		rows, err := s.db.Query("select key, value from meta")
+		rows, err := s.conn().Query("select key, value from meta")
 		if err != nil {
 			return nil, err
 		}
@@ -68,7 +77,7 @@ func (s *State) GetMeta() (*Meta, error) {
 }
 
 func (s *State) GetEntry(id uint64) (*types.Entry, error) {
-	row := s.db.QueryRow("select * from entries where id = ?", id)
+	row := s.conn().QueryRow("select * from entries where id = ?", id)
 
 	e, err := scanEntry(row)
 	if err == sql.ErrNoRows {
@@ -84,15 +93,69 @@ func (s *State) Close() error {
 	return s.db.Close()
 }
 
+// conn returns where queries run, the transaction of the dry run or the
+// database.
+func (s *State) conn() queryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// transaction runs fn in a transaction, which is committed when fn returns no
+// error.  The methods of the state fn calls run in the transaction too.
+// During a dry run fn runs in the transaction of the dry run.
+func (s *State) transaction(fn func(q queryer) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	s.tx = tx
+	err = fn(tx)
+	s.tx = nil
+
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// BeginDryRun runs everything after it in a transaction, until RollbackDryRun
+// throws the changes away.
+func (s *State) BeginDryRun() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	s.tx = tx
+	return nil
+}
+
+// RollbackDryRun throws away the changes since BeginDryRun.
+func (s *State) RollbackDryRun() error {
+	if s.tx == nil {
+		return nil
+	}
+	err := s.tx.Rollback()
+	s.tx = nil
+	return err
+}
+
 func (s *State) StartEntry(note, sheet string, start time.Time) (uint64, error) {
 	current, err := s.GetCurrentEntry()
 	if err != nil {
 		return 0, err
 	} else if current != nil {
-		return 0, errors.New("already running")
+		return 0, stateErrorf("already running")
 	}
 
-	res, err := s.db.Exec("insert into entries(note, start, sheet) values(?, ?, ?)", note, start, sheet)
+	res, err := s.conn().Exec("insert into entries(note, start, sheet) values(?, ?, ?)", note, start.UTC(), sheet)
 	if err != nil {
 		return 0, err
 	}
@@ -100,44 +163,104 @@ func (s *State) StartEntry(note, sheet string, start time.Time) (uint64, error)
 	id, err := res.LastInsertId()
 	return uint64(id), err
 }
+
+// AddEntries inserts the given entries in one transaction, the IDs of the
+// entries are set to the IDs of the inserted rows.
+func (s *State) AddEntries(entries []*types.Entry) error {
+	return s.transaction(func(q queryer) error {
+		for _, entry := range entries {
+			e := types.DatabaseEntryFromEntry(entry)
+			res, err := q.Exec(
+				"insert into entries(note, start, end, sheet) values(?, ?, ?, ?)",
+				e.Note,
+				e.Start,
+				e.End,
+				e.Sheet,
+			)
+			if err != nil {
+				return err
+			}
+
+			id, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			entry.ID = uint64(id)
+		}
+		return nil
+	})
+}
+
 func (s *State) StopEntry(id uint64, end time.Time) error {
 	entry, err := s.GetCurrentEntry()
 	if err != nil {
 		return err
 	} else if entry == nil {
-		return errors.New("not running")
+		return stateErrorf("not running")
 	}
 
 	if err := s.SetLastCheckoutId(id); err != nil {
 		return err
 	}
 
-	_, err = s.db.Exec("update entries set end = ? where id = ?", end, id)
+	_, err = s.conn().Exec("update entries set end = ? where id = ?", end.UTC(), id)
 	return err
 }
 func (s *State) EditEntry(id uint64, sheet, note string, start time.Time, end *time.Time) error {
-	_, err := s.db.Exec(
+	e := types.DatabaseEntryFromEntry(&types.Entry{Start: start, End: end})
+	_, err := s.conn().Exec(
 		"update entries set sheet = ?, note = ?, start = ?, end = ? where id = ?",
 		sheet,
 		note,
-		start,
-		end,
+		e.Start,
+		e.End,
 		id,
 	)
 	return err
 }
+
+// ApplyChanges inserts, updates and deletes the given entries in one
+// transaction.
+func (s *State) ApplyChanges(inserts, updates, deletes []*types.Entry) error {
+	return s.transaction(func(q queryer) error {
+		// the inserts get their ids, which the hooks of edit are given
+		if err := s.AddEntries(inserts); err != nil {
+			return err
+		}
+		for _, entry := range updates {
+			e := types.DatabaseEntryFromEntry(entry)
+			if _, err := q.Exec(
+				"update entries set sheet = ?, note = ?, start = ?, end = ? where id = ?",
+				e.Sheet,
+				e.Note,
+				e.Start,
+				e.End,
+				e.ID,
+			); err != nil {
+				return err
+			}
+		}
+		for _, entry := range deletes {
+			if _, err := q.Exec("delete from entries where id = ?", entry.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (s *State) RemoveEntry(id uint64) error {
-	_, err := s.db.Exec("delete from entries where id = ?", id)
+	_, err := s.conn().Exec("delete from entries where id = ?", id)
 	return err
 }
 
 func (s *State) SetLastCheckoutId(id uint64) error {
-	_, err := s.db.Exec("update meta set value = ? where key = ?", id, "last_checkout_id")
+	_, err := s.conn().Exec("update meta set value = ? where key = ?", id, "last_checkout_id")
 	return err
 }
 
 func (s *State) GetCurrentSheet() (string, error) {
-	row := s.db.QueryRow("select value from meta where key = ?", "current_sheet")
+	row := s.conn().QueryRow("select value from meta where key = ?", "current_sheet")
 	var res string
 	err := row.Scan(&res)
 	return res, err
@@ -145,7 +268,7 @@ func (s *State) GetCurrentSheet() (string, error) {
 
 func (s *State) GetCurrentEntry() (*types.Entry, error) {
 	// HACK
-	row := s.db.QueryRow("select id from entries where end is null")
+	row := s.conn().QueryRow("select id from entries where end is null")
 	var id uint64
 	err := row.Scan(&id)
 	if err == sql.ErrNoRows {
@@ -173,18 +296,19 @@ func (s *State) GetAllEntries(sheetName string) ([]*types.Entry, error) {
 	var rows *sql.Rows
 	var err error
 	if sheetName != "" {
-		rows, err = s.db.Query("select * from entries where sheet = ? order by start asc", sheetName)
+		rows, err = s.conn().Query("select * from entries where sheet = ? order by start asc", sheetName)
 	} else {
-		rows, err = s.db.Query("select * from entries order by start asc")
+		rows, err = s.conn().Query("select * from entries order by start asc")
 	}
 	if err != nil {
-		return res, nil
+		return res, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		e, err := scanEntry(rows)
 		if err != nil {
-			return res, nil
+			return res, err
 		}
 
 		entry, err := e.ToEntry()
@@ -194,25 +318,23 @@ func (s *State) GetAllEntries(sheetName string) ([]*types.Entry, error) {
 		res = append(res, entry)
 	}
 
-	return res, nil
+	return res, rows.Err()
 }
 
 func (s *State) SwitchSheet(sheet string) error {
-	tx, err := s.db.Begin()
-	if err != nil {
+	return s.transaction(func(q queryer) error {
+		if _, err := q.Exec("UPDATE meta SET value=(SELECT value FROM meta WHERE key='current_sheet') WHERE key='last_sheet'"); err != nil {
+			return err
+		}
+		_, err := q.Exec("UPDATE meta SET value=? WHERE key='current_sheet'", sheet)
 		return err
-	}
-
-	tx.Exec("UPDATE meta SET value=(SELECT value FROM meta WHERE key='current_sheet') WHERE key='last_sheet'")
-	tx.Exec("UPDATE meta SET value=? WHERE key='current_sheet'", sheet)
-
-	return tx.Commit()
+	})
 }
 
 func (s *State) GetAllSheets() ([]string, error) {
 	var res []string
 
-	rows, err := s.db.Query("select distinct sheet from entries")
+	rows, err := s.conn().Query("select distinct sheet from entries")
 	if err != nil {
 		return res, err
 	}
@@ -229,6 +351,108 @@ func (s *State) GetAllSheets() ([]string, error) {
 }
 
 func (s *State) RemoveSheet(name string) error {
-	_, err := s.db.Exec("delete from entries where sheet = ?", name)
+	_, err := s.conn().Exec("delete from entries where sheet = ?", name)
 	return err
 }
+
+// GetGoal returns the goal for the given sheet, or nil if the sheet has none.
+func (s *State) GetGoal(sheet string) (*types.Goal, error) {
+	row := s.conn().QueryRow("select daily, weekly from goals where sheet = ?", sheet)
+
+	var daily, weekly int64
+	err := row.Scan(&daily, &weekly)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &types.Goal{
+		Sheet:  sheet,
+		Daily:  time.Duration(daily) * time.Second,
+		Weekly: time.Duration(weekly) * time.Second,
+	}, nil
+}
+
+func (s *State) GetAllGoals() ([]*types.Goal, error) {
+	var res []*types.Goal
+
+	rows, err := s.conn().Query("select sheet, daily, weekly from goals order by sheet asc")
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sheet string
+		var daily, weekly int64
+		if err := rows.Scan(&sheet, &daily, &weekly); err != nil {
+			return res, err
+		}
+		res = append(res, &types.Goal{
+			Sheet:  sheet,
+			Daily:  time.Duration(daily) * time.Second,
+			Weekly: time.Duration(weekly) * time.Second,
+		})
+	}
+
+	return res, rows.Err()
+}
+
+// SetGoal sets the goal for the given sheet, if both durations are zero the
+// goal is removed.
+func (s *State) SetGoal(sheet string, daily, weekly time.Duration) error {
+	if daily == 0 && weekly == 0 {
+		_, err := s.conn().Exec("delete from goals where sheet = ?", sheet)
+		return err
+	}
+
+	_, err := s.conn().Exec(
+		"insert or replace into goals(sheet, daily, weekly) values(?, ?, ?)",
+		sheet,
+		int64(daily/time.Second),
+		int64(weekly/time.Second),
+	)
+	return err
+}
+
+func (s *State) SetPlannedEnd(id uint64, end time.Time) error {
+	_, err := s.conn().Exec("insert or replace into planned_ends(entry_id, end) values(?, ?)", id, end.UTC())
+	return err
+}
+
+// GetPlannedEnd returns the time the entry with the given ID should be stopped
+// at, or nil if it has none.
+func (s *State) GetPlannedEnd(id uint64) (*time.Time, error) {
+	row := s.conn().QueryRow("select end from planned_ends where entry_id = ?", id)
+
+	var end time.Time
+	err := row.Scan(&end)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	end = end.Local()
+	return &end, nil
+}
+
+// StopExpiredEntry stops the running entry at its planned end if that has
+// passed, and returns it.  Nil is returned if nothing was stopped.
+func (s *State) StopExpiredEntry(now time.Time) (*types.Entry, error) {
+	entry, err := s.GetCurrentEntry()
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	end, err := s.GetPlannedEnd(entry.ID)
+	if err != nil || end == nil || now.Before(*end) {
+		return nil, err
+	}
+
+	if err := s.StopEntry(entry.ID, *end); err != nil {
+		return nil, err
+	}
+	entry.End = end
+	return entry, nil
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"database/sql"
+	"got/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestState returns the state of a new database, the returned function
+// removes it.
+func newTestState(t *testing.T) (*State, func()) {
+	dir, err := ioutil.TempDir("", "got")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := getState(path.Join(dir, "timetrap.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return state, func() {
+		state.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// testEntry returns an entry of the sheet with the note, starting at 9:00 in
+// the local time zone on the given day of January 2026.
+func testEntry(day int, duration time.Duration, sheet, note string) *types.Entry {
+	start := time.Date(2026, 1, day, 9, 0, 0, 0, time.Local)
+	end := start.Add(duration)
+	return &types.Entry{Start: start, End: &end, Sheet: sheet, Note: note}
+}
+
+func TestStoreEntries(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("no time zone data: %s", err)
+	}
+	local := time.Local
+	time.Local = loc
+	defer func() {
+		time.Local = local
+	}()
+
+	state, remove := newTestState(t)
+	defer remove()
+
+	// the entry spans the change from 03:00 to 02:00, so it's 3 hours long
+	start := time.Date(2026, 10, 25, 1, 30, 0, 0, loc)
+	end := time.Date(2026, 10, 25, 3, 30, 0, 0, loc)
+	entries := []*types.Entry{
+		{Start: start, End: &end, Sheet: "main", Note: "night"},
+		{Start: end, Sheet: "main", Note: "running"},
+	}
+	if err := state.AddEntries(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		var stored string
+		if err := state.db.QueryRow("select cast(start as text) from entries where id = ?", entry.ID).Scan(&stored); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(stored, "+00:00") {
+			t.Errorf("entry #%d is stored as %s, expected a time in UTC", entry.ID, stored)
+		}
+
+		res, err := state.GetEntry(entry.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Start.Equal(entry.Start) || res.Start.Location() != time.Local {
+			t.Errorf("entry #%d starts at %s, expected %s in the local zone", entry.ID, res.Start, entry.Start)
+		}
+		if (res.End == nil) != (entry.End == nil) || res.End != nil && !res.End.Equal(*entry.End) {
+			t.Errorf("entry #%d ends at %v, expected %v", entry.ID, res.End, entry.End)
+		}
+		if res.Sheet != entry.Sheet || res.Note != entry.Note {
+			t.Errorf("entry #%d is %+v, expected %+v", entry.ID, res, entry)
+		}
+	}
+
+	if d, _ := entries[0].Duration(); d != 3*time.Hour {
+		t.Errorf("the entry over the change is %s long, expected 3h", d)
+	}
+
+	running, err := state.GetCurrentEntry()
+	if err != nil {
+		t.Fatal(err)
+	} else if running == nil || running.ID != entries[1].ID {
+		t.Errorf("the running entry is %+v, expected #%d", running, entries[1].ID)
+	}
+}
+
+func TestMigrationsToUTC(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	// timetrap stores times without an offset, in the local time of the
+	// system, which is the zone time.Local has by default
+	timetrap := time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local).UTC().Format("2006-01-02 15:04:05.000") + "+00:00"
+
+	tests := []struct {
+		start, end      string
+		expectedStart   string
+		expectedEnd     string
+		expectedNullEnd bool
+	}{
+		// written by timetrap
+		{"2026-01-05 09:00:00.000000", "2026-01-05 09:00:00", timetrap, timetrap, false},
+		// written by older versions of got
+		{"2026-01-05 09:00:00.123456789+01:00", "2026-01-05 04:00:00-05:00", "2026-01-05 08:00:00.123+00:00", "2026-01-05 09:00:00.000+00:00", false},
+		// already in UTC
+		{"2026-01-05 08:00:00.5+00:00", "", "2026-01-05 08:00:00.5+00:00", "", true},
+	}
+
+	for _, test := range tests {
+		var end interface{}
+		if test.end != "" {
+			end = test.end
+		}
+		if _, err := state.db.Exec("insert into entries(note, start, end, sheet) values('', ?, ?, 'main')", test.start, end); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := state.db.Exec("insert into planned_ends(entry_id, end) values(3, '2026-01-05 12:00:00+01:00')"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the database is migrated like one of timetrap, which has no version
+	if _, err := state.db.Exec("delete from meta where key = 'schema_version'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runMigrations(state.db); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range tests {
+		var start string
+		var end *string
+		if err := state.db.QueryRow("select cast(start as text), cast(end as text) from entries where id = ?", i+1).Scan(&start, &end); err != nil {
+			t.Fatal(err)
+		}
+
+		if start != test.expectedStart {
+			t.Errorf("%s is migrated to %s, expected %s", test.start, start, test.expectedStart)
+		}
+		if test.expectedNullEnd {
+			if end != nil {
+				t.Errorf("no end is migrated to %s", *end)
+			}
+		} else if end == nil || *end != test.expectedEnd {
+			t.Errorf("%s is migrated to %v, expected %s", test.end, end, test.expectedEnd)
+		}
+	}
+
+	var plannedEnd string
+	if err := state.db.QueryRow("select cast(end as text) from planned_ends where entry_id = 3").Scan(&plannedEnd); err != nil {
+		t.Fatal(err)
+	}
+	if plannedEnd != "2026-01-05 11:00:00.000+00:00" {
+		t.Errorf("the planned end is migrated to %s, expected 2026-01-05 11:00:00.000+00:00", plannedEnd)
+	}
+}
+
+func TestMigrationsRunOnce(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	var version int
+	if err := state.db.QueryRow("select value from meta where key = 'schema_version'").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Errorf("the schema version is %d, expected %d", version, len(migrations))
+	}
+
+	// a second start opens the database read-only, so it fails when it
+	// writes
+	var seq int
+	var name, fname string
+	if err := state.db.QueryRow("pragma database_list").Scan(&seq, &name, &fname); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+fname+"?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := runMigrations(db); err != nil {
+		t.Errorf("migrating again: %s", err)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	err := state.transaction(func(queryer) error {
+		id, err := state.StartEntry("", "main", start)
+		if err != nil {
+			return err
+		}
+		return state.SetPlannedEnd(id, start.Add(time.Hour))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failing statement rolls back the ones before it
+	err = state.transaction(func(q queryer) error {
+		if err := state.StopEntry(1, start.Add(time.Hour)); err != nil {
+			return err
+		}
+		_, err := q.Exec("insert into nothing values(1)")
+		return err
+	})
+	if err == nil {
+		t.Fatal("inserting into a table that does not exist gave no error")
+	}
+
+	entry, err := state.GetCurrentEntry()
+	if err != nil {
+		t.Fatal(err)
+	} else if entry == nil || entry.ID != 1 {
+		t.Fatalf("the running entry is %+v, expected #1 to be running still", entry)
+	}
+
+	end, err := state.GetPlannedEnd(entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if end == nil || !end.Equal(start.Add(time.Hour)) {
+		t.Errorf("the planned end is %v, expected %s", end, start.Add(time.Hour))
+	}
+}
+
+func TestSwitchSheet(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	if err := state.SwitchSheet("work"); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := state.GetMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.CurrentSheet != "work" || meta.LastSheet != "main" {
+		t.Errorf("the sheet is %s and the last sheet %s, expected work and main", meta.CurrentSheet, meta.LastSheet)
+	}
+
+	if _, err := state.db.Exec("drop table meta"); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.SwitchSheet("home"); err == nil {
+		t.Errorf("switching without a meta table gave no error")
+	}
+}
+
+func TestGetAllEntriesErrors(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	if _, err := state.db.Exec("insert into entries (note, start, end, sheet) values ('x', null, null, 'main')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := state.GetAllEntries(""); err == nil {
+		t.Errorf("an entry without a start gave no error")
+	}
+
+	if _, err := state.db.Exec("drop table entries"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := state.GetAllEntries("main"); err == nil {
+		t.Errorf("getting the entries without an entries table gave no error")
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	state, remove := newTestState(t)
+	defer remove()
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	kept := &types.Entry{Start: start, End: &end, Sheet: "main", Note: "kept"}
+	deleted := &types.Entry{Start: start, End: &end, Sheet: "main", Note: "deleted"}
+	if err := state.AddEntries([]*types.Entry{kept, deleted}); err != nil {
+		t.Fatal(err)
+	}
+
+	kept.Note = "updated"
+	inserted := &types.Entry{Start: end, Sheet: "main", Note: "inserted"}
+	if err := state.ApplyChanges([]*types.Entry{inserted}, []*types.Entry{kept}, []*types.Entry{deleted}); err != nil {
+		t.Fatal(err)
+	}
+	if inserted.ID == 0 {
+		t.Errorf("the inserted entry has no id")
+	}
+
+	entries, err := state.GetAllEntries("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Note != "updated" || entries[1].ID != inserted.ID || entries[1].Note != "inserted" {
+		t.Errorf("the entries are %v, expected the updated and the inserted entry", entries)
+	}
+}
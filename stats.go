@@ -0,0 +1,127 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"got/utils"
+	"sort"
+	"time"
+)
+
+// maxNoteStats is the amount of most frequent notes in the stats.
+const maxNoteStats = 5
+
+// BuildStats computes the stats of the given entries that started between
+// start and end (zero values meaning unbounded).  The average is per workday
+// of the schedule, from start or the first tracked day until end or today.
+func BuildStats(entries []*types.Entry, schedule *config.Schedule, start, end, now time.Time) *types.Stats {
+	stats := &types.Stats{}
+	if start != (time.Time{}) {
+		stats.Start = &start
+	}
+	if end != (time.Time{}) {
+		stats.End = &end
+	}
+
+	var days []*types.DayStats
+	switches := 0
+	notes := make(map[string]*types.NoteStats)
+
+	var prev *types.Entry
+	for _, entry := range entries {
+		if stats.Start != nil && entry.Start.Before(start) {
+			continue
+		} else if stats.End != nil && !entry.Start.Before(end) {
+			continue
+		}
+
+		duration, _ := entry.Duration()
+		stats.Entries++
+		stats.Total += duration
+
+		if prev == nil || !utils.SameDate(prev.Start, entry.Start) {
+			days = append(days, &types.DayStats{Date: utils.StartOfDay(entry.Start)})
+		} else if prev.Sheet != entry.Sheet || prev.Note != entry.Note {
+			switches++
+		}
+		days[len(days)-1].Duration += duration
+		prev = entry
+
+		note, has := notes[entry.Note]
+		if !has {
+			note = &types.NoteStats{Note: entry.Note}
+			notes[entry.Note] = note
+		}
+		note.Count++
+		note.Duration += duration
+	}
+
+	stats.Days = len(days)
+	if stats.Days == 0 {
+		return stats
+	}
+
+	first := days[0].Date
+	if stats.Start != nil {
+		first = utils.StartOfDay(start)
+	}
+	limit := utils.StartOfDay(now).AddDate(0, 0, 1)
+	if stats.End != nil && end.Before(limit) {
+		limit = end
+	}
+	for day := first; day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if schedule.Expected(day) > 0 {
+			stats.Workdays++
+		}
+	}
+	if stats.Workdays > 0 {
+		stats.AverageWorkday = stats.Total / time.Duration(stats.Workdays)
+	}
+	stats.AverageEntry = stats.Total / time.Duration(stats.Entries)
+	stats.AverageSwitches = float64(switches) / float64(stats.Days)
+
+	streak := 0
+	for i, day := range days {
+		if d := *day; stats.LongestDay == nil || d.Duration > stats.LongestDay.Duration {
+			stats.LongestDay = &d
+		}
+		if d := *day; stats.ShortestDay == nil || d.Duration < stats.ShortestDay.Duration {
+			stats.ShortestDay = &d
+		}
+
+		if i > 0 && utils.SameDate(days[i-1].Date.AddDate(0, 0, 1), day.Date) {
+			streak++
+		} else {
+			streak = 1
+		}
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+	}
+
+	// the current streak is only still going if something was tracked today
+	// or yesterday
+	last := days[len(days)-1].Date
+	today := utils.StartOfDay(now)
+	if utils.SameDate(last, today) || utils.SameDate(last.AddDate(0, 0, 1), today) {
+		stats.CurrentStreak = streak
+	}
+
+	for _, note := range notes {
+		stats.Notes = append(stats.Notes, *note)
+	}
+	sort.Slice(stats.Notes, func(i, j int) bool {
+		a, b := stats.Notes[i], stats.Notes[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		} else if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.Note < b.Note
+	})
+	if len(stats.Notes) > maxNoteStats {
+		stats.Notes = stats.Notes[:maxNoteStats]
+	}
+
+	return stats
+}
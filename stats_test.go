@@ -0,0 +1,57 @@
+package main
+
+import (
+	"got/config"
+	"got/types"
+	"testing"
+	"time"
+)
+
+func TestBuildStatsAveragePerWorkday(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2026, 1, d, hour, 0, 0, 0, time.Local)
+	}
+
+	holidays := config.DefaultSchedule()
+	holidays.DaysOff["2026-01-09"] = config.DayOff{Date: day(9, 0)}
+
+	// Monday January 5, Tuesday and Saturday are tracked
+	entries := []*types.Entry{
+		testEntry(5, 8*time.Hour, "main", "work"),
+		testEntry(6, 4*time.Hour, "main", "work"),
+		testEntry(10, 3*time.Hour, "main", "work"),
+	}
+
+	tests := []struct {
+		schedule   *config.Schedule
+		start, end time.Time
+		now        time.Time
+		workdays   int
+		average    time.Duration
+	}{
+		// the week, the weekend is not a workday
+		{config.DefaultSchedule(), day(5, 0), day(12, 0), day(20, 12), 5, 3 * time.Hour},
+		// with the Friday off
+		{holidays, day(5, 0), day(12, 0), day(20, 12), 4, 3*time.Hour + 45*time.Minute},
+		// from the first tracked day until today
+		{config.DefaultSchedule(), time.Time{}, time.Time{}, day(13, 12), 7, 15 * time.Hour / 7},
+		// the end is after today
+		{config.DefaultSchedule(), day(1, 0), day(31, 0), day(10, 12), 7, 15 * time.Hour / 7},
+	}
+
+	for i, test := range tests {
+		stats := BuildStats(entries, test.schedule, test.start, test.end, test.now)
+		if stats.Days != 3 || stats.Total != 15*time.Hour {
+			t.Errorf("test %d: %d days and %s are tracked, expected 3 days and 15h", i, stats.Days, stats.Total)
+		}
+		if stats.Workdays != test.workdays || stats.AverageWorkday != test.average {
+			t.Errorf("test %d: the average of %d workdays is %s, expected %s of %d", i, stats.Workdays, stats.AverageWorkday, test.average, test.workdays)
+		}
+	}
+
+	// nothing is expected on the weekend
+	stats := BuildStats(entries, config.DefaultSchedule(), day(10, 0), day(12, 0), day(20, 12))
+	if stats.Workdays != 0 || stats.AverageWorkday != 0 {
+		t.Errorf("the weekend has %d workdays and an average of %s, expected none", stats.Workdays, stats.AverageWorkday)
+	}
+}
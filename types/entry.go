@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,17 @@ func (e *Entry) Duration() (time.Duration, bool) {
 	}
 }
 
+// Tags returns the words in the note that start with a '#', without the '#'.
+func (e *Entry) Tags() []string {
+	var res []string
+	for _, word := range strings.Fields(e.Note) {
+		if len(word) > 1 && word[0] == '#' {
+			res = append(res, word[1:])
+		}
+	}
+	return res
+}
+
 type DatabaseEntry struct {
 	ID    uint64
 	Start time.Time
@@ -30,10 +42,13 @@ type DatabaseEntry struct {
 	Note  string
 }
 
+// DatabaseEntryFromEntry returns the entry as it is stored, with the times in
+// UTC.
 func DatabaseEntryFromEntry(e *Entry) DatabaseEntry {
 	var end *time.Time
 	if e.End != nil {
-		*end = e.End.Local()
+		utc := e.End.UTC()
+		end = &utc
 	}
 
 	return DatabaseEntry{
@@ -44,11 +59,20 @@ func DatabaseEntryFromEntry(e *Entry) DatabaseEntry {
 		Note:  e.Note,
 	}
 }
+
+// ToEntry returns the entry with the times in the local time zone, which is
+// the zone entries are displayed in.
 func (e DatabaseEntry) ToEntry() (*Entry, error) {
+	var end *time.Time
+	if e.End != nil {
+		local := e.End.Local()
+		end = &local
+	}
+
 	return &Entry{
 		ID:    e.ID,
-		Start: e.Start,
-		End:   e.End,
+		Start: e.Start.Local(),
+		End:   end,
 		Sheet: e.Sheet,
 		Note:  e.Note,
 	}, nil
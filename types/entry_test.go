@@ -0,0 +1,52 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatabaseEntryFromEntry(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, loc)
+	end := time.Date(2026, 1, 5, 10, 30, 0, 0, loc)
+
+	e := DatabaseEntryFromEntry(&Entry{ID: 1, Start: start, End: &end, Sheet: "main", Note: "a"})
+	if e.Start.Location() != time.UTC || !e.Start.Equal(start) {
+		t.Errorf("start is %s, expected %s in UTC", e.Start, start)
+	}
+	if e.End == nil || e.End.Location() != time.UTC || !e.End.Equal(end) {
+		t.Errorf("end is %v, expected %s in UTC", e.End, end)
+	}
+	if e.ID != 1 || e.Sheet != "main" || e.Note != "a" {
+		t.Errorf("entry is %+v, expected ID 1 in sheet main with note a", e)
+	}
+
+	running := DatabaseEntryFromEntry(&Entry{Start: start})
+	if running.End != nil {
+		t.Errorf("end of a running entry is %s, expected nil", running.End)
+	}
+}
+
+func TestToEntry(t *testing.T) {
+	start := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	e, err := DatabaseEntry{Start: start, End: &end}.ToEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Start.Location() != time.Local || !e.Start.Equal(start) {
+		t.Errorf("start is %s, expected %s in the local zone", e.Start, start)
+	}
+	if e.End == nil || e.End.Location() != time.Local || !e.End.Equal(end) {
+		t.Errorf("end is %v, expected %s in the local zone", e.End, end)
+	}
+
+	running, err := DatabaseEntry{Start: start}.ToEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if running.End != nil {
+		t.Errorf("end of a running entry is %s, expected nil", running.End)
+	}
+}
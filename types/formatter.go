@@ -5,8 +5,12 @@ import "io"
 type FormatterInput struct {
 	Sheet   string
 	Entries []*Entry
+	// Rounding is nil when the durations are not rounded.
+	Rounding *Rounding
 }
 
 type Formatter interface {
 	Write(w io.Writer, input *FormatterInput) error
+	WriteReport(w io.Writer, report *Report) error
+	WriteStats(w io.Writer, stats *Stats) error
 }
@@ -0,0 +1,13 @@
+package types
+
+import (
+	"time"
+)
+
+// Goal is the amount of time that should be spent on a sheet per day and per
+// week, a zero duration means there is no goal.
+type Goal struct {
+	Sheet  string
+	Daily  time.Duration
+	Weekly time.Duration
+}
@@ -0,0 +1,23 @@
+package types
+
+import (
+	"time"
+)
+
+// ReportGroup is a single node in a report, it holds the time spent on the
+// entries that fall in the group and, if the report is grouped on more than
+// one key, the sub groups.
+type ReportGroup struct {
+	Key        string
+	Duration   time.Duration
+	Percentage float64
+	Groups     []*ReportGroup
+}
+
+type Report struct {
+	GroupBy []string
+	Start   *time.Time
+	End     *time.Time
+	Total   time.Duration
+	Groups  []*ReportGroup
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// The rounding modes.
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// Rounding rounds durations for display, the stored timestamps are never
+// rounded.  With PerDay the durations of the entries are summed per day and
+// only the day totals are rounded, otherwise every entry is rounded.
+type Rounding struct {
+	To     time.Duration
+	Mode   string
+	PerDay bool
+}
+
+// ValidateRoundingMode returns an error for unknown rounding modes.
+func ValidateRoundingMode(mode string) error {
+	switch mode {
+	case RoundNearest, RoundUp, RoundDown:
+		return nil
+	}
+	return fmt.Errorf("invalid rounding mode %s, can be '%s', '%s' or '%s'", mode, RoundNearest, RoundUp, RoundDown)
+}
+
+// Round rounds d to a multiple of To, a nil rounding does not round.
+func (r *Rounding) Round(d time.Duration) time.Duration {
+	if r == nil || r.To <= 0 {
+		return d
+	}
+
+	switch r.Mode {
+	case RoundUp:
+		if rest := d % r.To; rest > 0 {
+			return d - rest + r.To
+		}
+		return d
+	case RoundDown:
+		return d - d%r.To
+	}
+	return d.Round(r.To)
+}
+
+// Entry rounds the duration of a single entry, which is only rounded when
+// rounding per entry.
+func (r *Rounding) Entry(d time.Duration) time.Duration {
+	if r == nil || r.PerDay {
+		return d
+	}
+	return r.Round(d)
+}
+
+// Day rounds the total of the durations of a day as returned by Entry, which
+// is only rounded when rounding per day.
+func (r *Rounding) Day(d time.Duration) time.Duration {
+	if r == nil || !r.PerDay {
+		return d
+	}
+	return r.Round(d)
+}
+
+// Sum returns the rounded total duration of the entries for which fn returns
+// true.
+func (r *Rounding) Sum(entries []*Entry, fn func(*Entry) bool) time.Duration {
+	days := make(map[string]time.Duration)
+	for _, entry := range entries {
+		if fn(entry) {
+			duration, _ := entry.Duration()
+			days[entry.Start.Format("2006-01-02")] += r.Entry(duration)
+		}
+	}
+
+	var res time.Duration
+	for _, duration := range days {
+		res += r.Day(duration)
+	}
+	return res
+}
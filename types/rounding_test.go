@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		mode     string
+		d        time.Duration
+		expected time.Duration
+	}{
+		{RoundNearest, 7 * time.Minute, 0},
+		{RoundNearest, 8 * time.Minute, 15 * time.Minute},
+		{RoundNearest, 52 * time.Minute, 45 * time.Minute},
+		{RoundUp, time.Minute, 15 * time.Minute},
+		{RoundUp, 15 * time.Minute, 15 * time.Minute},
+		{RoundDown, 29 * time.Minute, 15 * time.Minute},
+		{RoundDown, 30 * time.Minute, 30 * time.Minute},
+	}
+
+	for _, test := range tests {
+		r := &Rounding{To: 15 * time.Minute, Mode: test.mode}
+		if d := r.Round(test.d); d != test.expected {
+			t.Errorf("rounding %s %s gives %s, expected %s", test.d, test.mode, d, test.expected)
+		}
+	}
+
+	var none *Rounding
+	if d := none.Round(7 * time.Minute); d != 7*time.Minute {
+		t.Errorf("a nil rounding rounds 7m to %s", d)
+	}
+	if d := (&Rounding{Mode: RoundUp}).Round(7 * time.Minute); d != 7*time.Minute {
+		t.Errorf("rounding to 0 rounds 7m to %s", d)
+	}
+}
+
+func TestRoundingSum(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+	nextStart := start.AddDate(0, 0, 1)
+	nextEnd := nextStart.Add(20 * time.Minute)
+	entries := []*Entry{
+		{Start: start, End: &end},
+		{Start: start, End: &end},
+		{Start: nextStart, End: &nextEnd},
+	}
+	all := func(*Entry) bool { return true }
+
+	tests := []struct {
+		rounding *Rounding
+		expected time.Duration
+	}{
+		{nil, 40 * time.Minute},
+		// every entry is rounded up to 15m
+		{&Rounding{To: 15 * time.Minute, Mode: RoundUp}, 60 * time.Minute},
+		// the days are 20m and 20m, rounded up to 30m each
+		{&Rounding{To: 15 * time.Minute, Mode: RoundUp, PerDay: true}, 60 * time.Minute},
+		// the days are rounded to the nearest 15m, 15m each
+		{&Rounding{To: 15 * time.Minute, Mode: RoundNearest, PerDay: true}, 30 * time.Minute},
+	}
+
+	for _, test := range tests {
+		if sum := test.rounding.Sum(entries, all); sum != test.expected {
+			t.Errorf("sum with rounding %+v is %s, expected %s", test.rounding, sum, test.expected)
+		}
+	}
+}
+
+func TestValidateRoundingMode(t *testing.T) {
+	for _, mode := range []string{RoundNearest, RoundUp, RoundDown} {
+		if err := ValidateRoundingMode(mode); err != nil {
+			t.Errorf("mode %s is invalid: %s", mode, err)
+		}
+	}
+	if err := ValidateRoundingMode("sideways"); err == nil {
+		t.Errorf("mode sideways is valid")
+	}
+}
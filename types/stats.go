@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+)
+
+type DayStats struct {
+	Date     time.Time
+	Duration time.Duration
+}
+
+type NoteStats struct {
+	Note     string
+	Count    int
+	Duration time.Duration
+}
+
+// Stats is a summary of the entries between Start and End, which are nil when
+// unbounded.
+type Stats struct {
+	Start *time.Time
+	End   *time.Time
+
+	Entries int
+	Days    int
+	// Workdays are the days in the range on which work is expected by the
+	// schedule, up to today.
+	Workdays int
+	Total    time.Duration
+
+	AverageWorkday time.Duration
+	AverageEntry   time.Duration
+	LongestDay     *DayStats
+	ShortestDay    *DayStats
+
+	LongestStreak int
+	CurrentStreak int
+
+	// AverageSwitches is the average number of times per day the sheet or
+	// note changes between consecutive entries.
+	AverageSwitches float64
+
+	Notes []NoteStats
+}
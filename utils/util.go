@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"bufio"
 	"fmt"
 	"got/types"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -22,9 +24,10 @@ func FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%01d:%02d:%02d", h, m, s)
 }
 
+// SameDate returns whether a and b are on the same day in the local time zone.
 func SameDate(a, b time.Time) bool {
-	yA, mA, dA := a.Date()
-	yB, mB, dB := b.Date()
+	yA, mA, dA := a.Local().Date()
+	yB, mB, dB := b.Local().Date()
 
 	return yA == yB && mA == mB && dA == dB
 }
@@ -36,6 +39,21 @@ func ParseDate(str string) (time.Time, error) {
 	return time.Parse("2006-01-02 15:04:05.000000", str)
 }
 
+// Answer is how confirmations are answered.
+type Answer int
+
+const (
+	// AnswerAsk asks the user, or refuses when stdin is not a terminal.
+	AnswerAsk Answer = iota
+	AnswerYes
+	AnswerNo
+)
+
+// AssumedAnswer answers every confirmation, set by --yes and --no.
+var AssumedAnswer = AnswerAsk
+
+// Confirm asks the user the yes or no question in prompt, unless an answer is
+// assumed.  When stdin is not a terminal nobody can answer, so it refuses.
 func Confirm(prompt string, defaultValue bool) bool {
 	var hint string
 	if defaultValue {
@@ -48,6 +66,24 @@ func Confirm(prompt string, defaultValue bool) bool {
 		fmt.Fprintln(os.Stderr, "")
 	}()
 
+	switch {
+	case AssumedAnswer == AnswerYes:
+		fmt.Fprintf(os.Stderr, "%s yes", prompt)
+		return true
+	case AssumedAnswer == AnswerNo:
+		fmt.Fprintf(os.Stderr, "%s no", prompt)
+		return false
+	case !IsTerminal():
+		fmt.Fprintf(os.Stderr, "%s no, stdin is not a terminal (use --yes to confirm)", prompt)
+		return false
+	}
+
+	return ask(prompt, hint, defaultValue)
+}
+
+// ask asks the question until it is answered with yes or no, an empty answer
+// or the end of stdin gives the default value.
+func ask(prompt, hint string, defaultValue bool) bool {
 	for {
 		fmt.Fprintf(
 			os.Stderr,
@@ -56,20 +92,51 @@ func Confirm(prompt string, defaultValue bool) bool {
 			hint,
 		)
 
-		var str string
-		if _, err := fmt.Scanf("%s", &str); err != nil {
+		str, err := ReadLine()
+		if err != nil {
 			return defaultValue
 		}
 
-		lower := strings.ToLower(str)
-		if lower[0] == 'y' || lower[0] == 'n' {
-			return lower[0] == 'y'
-		} else if lower == "" {
+		lower := strings.ToLower(strings.TrimSpace(str))
+		if lower == "" {
 			return defaultValue
+		} else if lower[0] == 'y' || lower[0] == 'n' {
+			return lower[0] == 'y'
 		}
 	}
 }
 
+// stdin is shared by everything that reads from the user, so no input is lost
+// in the buffer of another reader.
+var stdin = bufio.NewReader(os.Stdin)
+
+// ReadLine reads a line from stdin, without the trailing newline.
+func ReadLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Prompt writes the prompt to stderr and reads the answer from stdin.
+func Prompt(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	return ReadLine()
+}
+
+// IsTerminal returns whether stdin is a terminal, so the user can be asked
+// for input.  The null device is a character device too, but not a terminal.
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
 func SumDuration(entries []*types.Entry, fn func(*types.Entry) bool) time.Duration {
 	var res time.Duration
 
@@ -90,3 +157,62 @@ func GetNth(entries []*types.Entry, i int) *types.Entry {
 
 	return nil
 }
+
+// FormatSignedDuration is like FormatDuration but always prefixes the
+// duration with its sign.
+func FormatSignedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	return "+" + FormatDuration(d)
+}
+
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns the start of the first day of the week t is in, for
+// weeks starting on weekStart.
+func StartOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return StartOfDay(t).AddDate(0, 0, -offset)
+}
+
+// SplitWords splits str on whitespace like a shell does, text in single or
+// double quotes is kept together.
+func SplitWords(str string) ([]string, error) {
+	var res []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	for _, c := range str {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				res = append(res, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %s", str)
+	}
+	if inWord {
+		res = append(res, word.String())
+	}
+	return res, nil
+}
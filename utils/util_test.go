@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inZone sets the local time zone to the given zone like --tz does, the
+// returned function restores it.
+func inZone(t *testing.T, name string) (*time.Location, func()) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no time zone data for %s: %s", name, err)
+	}
+
+	local := time.Local
+	time.Local = loc
+	return loc, func() {
+		time.Local = local
+	}
+}
+
+func TestSameDateAcrossDST(t *testing.T) {
+	_, restore := inZone(t, "Europe/Amsterdam")
+	defer restore()
+
+	utc := func(str string) time.Time {
+		res, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		// the clocks go from 02:00 to 03:00 on March 29, 2026, which is a day
+		// of 23 hours, from 23:00 UTC to 22:00 UTC
+		{"2026-03-28T23:30:00Z", "2026-03-29T21:30:00Z", true},
+		{"2026-03-28T22:30:00Z", "2026-03-28T23:30:00Z", false},
+		{"2026-03-29T21:30:00Z", "2026-03-29T22:30:00Z", false},
+		// the clocks go from 03:00 to 02:00 on October 25, 2026, which is a
+		// day of 25 hours, from 22:00 UTC to 23:00 UTC
+		{"2026-10-24T22:30:00Z", "2026-10-25T22:30:00Z", true},
+		{"2026-10-24T21:30:00Z", "2026-10-24T22:30:00Z", false},
+		{"2026-10-25T22:30:00Z", "2026-10-25T23:30:00Z", false},
+	}
+
+	for _, test := range tests {
+		if same := SameDate(utc(test.a), utc(test.b)); same != test.same {
+			t.Errorf("SameDate(%s, %s) = %v, expected %v", test.a, test.b, same, test.same)
+		}
+	}
+}
+
+func TestStartOfDayAcrossDST(t *testing.T) {
+	loc, restore := inZone(t, "Europe/Amsterdam")
+	defer restore()
+
+	tests := []struct {
+		day    time.Time
+		start  string
+		length time.Duration
+	}{
+		{time.Date(2026, 3, 29, 15, 0, 0, 0, loc), "2026-03-29T00:00:00+01:00", 23 * time.Hour},
+		{time.Date(2026, 6, 1, 15, 0, 0, 0, loc), "2026-06-01T00:00:00+02:00", 24 * time.Hour},
+		{time.Date(2026, 10, 25, 15, 0, 0, 0, loc), "2026-10-25T00:00:00+02:00", 25 * time.Hour},
+	}
+
+	for _, test := range tests {
+		start := StartOfDay(test.day)
+		if str := start.Format(time.RFC3339); str != test.start {
+			t.Errorf("StartOfDay(%s) = %s, expected %s", test.day, str, test.start)
+		}
+
+		next := StartOfDay(start.AddDate(0, 0, 1))
+		if length := next.Sub(start); length != test.length {
+			t.Errorf("the day of %s is %s long, expected %s", test.day, length, test.length)
+		}
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	// Thursday
+	day := time.Date(2026, 1, 8, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		weekStart time.Weekday
+		expected  time.Time
+	}{
+		{time.Monday, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{time.Sunday, time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{time.Thursday, time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{time.Friday, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		if start := StartOfWeek(day, test.weekStart); !start.Equal(test.expected) {
+			t.Errorf("StartOfWeek(%s, %s) = %s, expected %s", day, test.weekStart, start, test.expected)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected []string
+	}{
+		{"in --note 'a b'", []string{"in", "--note", "a b"}},
+		{`display "" x`, []string{"display", "", "x"}},
+		{"  out  ", []string{"out"}},
+	}
+
+	for _, test := range tests {
+		words, err := SplitWords(test.str)
+		if err != nil {
+			t.Errorf("SplitWords(%s): %s", test.str, err)
+			continue
+		}
+		if len(words) != len(test.expected) {
+			t.Errorf("SplitWords(%s) = %q, expected %q", test.str, words, test.expected)
+			continue
+		}
+		for i := range words {
+			if words[i] != test.expected[i] {
+				t.Errorf("SplitWords(%s) = %q, expected %q", test.str, words, test.expected)
+				break
+			}
+		}
+	}
+
+	if _, err := SplitWords("in 'a b"); err == nil {
+		t.Errorf("SplitWords with an unterminated quote gave no error")
+	}
+}
+
+func TestConfirmAssumedAnswer(t *testing.T) {
+	assumed := AssumedAnswer
+	defer func() {
+		AssumedAnswer = assumed
+	}()
+
+	tests := []struct {
+		answer       Answer
+		defaultValue bool
+		expected     bool
+	}{
+		{AnswerYes, false, true},
+		{AnswerNo, true, false},
+		// stdin of the tests is not a terminal, so nobody can answer
+		{AnswerAsk, true, false},
+		{AnswerAsk, false, false},
+	}
+
+	for _, test := range tests {
+		AssumedAnswer = test.answer
+		if answer := Confirm("delete?", test.defaultValue); answer != test.expected {
+			t.Errorf("Confirm with the answer %d and the default %v = %v, expected %v", test.answer, test.defaultValue, answer, test.expected)
+		}
+	}
+}
+
+func TestAsk(t *testing.T) {
+	reader := stdin
+	defer func() {
+		stdin = reader
+	}()
+
+	tests := []struct {
+		input        string
+		defaultValue bool
+		expected     bool
+	}{
+		{"y\n", false, true},
+		{"No\n", true, false},
+		{"yes", false, true},
+		// the default answer
+		{"\n", true, true},
+		{"  \n", false, false},
+		{"", true, true},
+		// other answers ask again
+		{"maybe\nn\n", true, false},
+		{"maybe\n", true, true},
+	}
+
+	for _, test := range tests {
+		stdin = bufio.NewReader(strings.NewReader(test.input))
+		if answer := ask("delete?", "y/N", test.defaultValue); answer != test.expected {
+			t.Errorf("answering %q with the default %v = %v, expected %v", test.input, test.defaultValue, answer, test.expected)
+		}
+	}
+}